@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Stats counts how a cache has been used since it was created.
+type Stats struct {
+	Hits        uint64 `json:"hits"`
+	Misses      uint64 `json:"misses"`
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+	StaleHits   uint64 `json:"staleHits"`
+	Entries     int    `json:"entries"`
+}
+
+// An entry of the lru, kept in its list.
+type entry struct {
+	key       string
+	value     interface{}
+	storedAt  time.Time
+	expiresAt time.Time
+}
+
+// Least recently used cache with expiring entries, safe for concurrent use.
+// Once full, adding an entry evicts the one that was used the longest ago.
+// Expired entries are kept until staleFor after they were stored, so that they can still be used when there is nothing better, see getStale.
+type lru struct {
+	mutex      sync.Mutex
+	maxEntries int
+	staleFor   time.Duration
+	entries    map[string]*list.Element
+	order      *list.List
+	stats      Stats
+}
+
+// newLRU creates a cache holding at most maxEntries entries, zero or less meaning no limit, keeping expired ones until staleFor after they were stored.
+func newLRU(maxEntries int, staleFor time.Duration) *lru {
+	return &lru{maxEntries: maxEntries, staleFor: staleFor, entries: map[string]*list.Element{}, order: list.New()}
+}
+
+// get returns the value for the key and when it was stored, unless missing or expired at the given time.
+func (cache *lru) get(key string, now time.Time) (interface{}, time.Time, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cached, found := cache.lookup(key, now)
+	if !found || now.After(cached.expiresAt) {
+		cache.stats.Misses++
+		return nil, time.Time{}, false
+	}
+
+	cache.stats.Hits++
+	return cached.value, cached.storedAt, true
+}
+
+// getStale returns the value for the key and when it was stored, even if expired, as long as it is still kept.
+func (cache *lru) getStale(key string, now time.Time) (interface{}, time.Time, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cached, found := cache.lookup(key, now)
+	if !found {
+		return nil, time.Time{}, false
+	}
+
+	cache.stats.StaleHits++
+	return cached.value, cached.storedAt, true
+}
+
+// lookup finds the entry for the key, removing it if kept for too long already.
+func (cache *lru) lookup(key string, now time.Time) (*entry, bool) {
+	element, found := cache.entries[key]
+	if !found {
+		return nil, false
+	}
+	cached := element.Value.(*entry)
+	if now.After(cached.expiresAt) && now.After(cached.storedAt.Add(cache.staleFor)) {
+		cache.remove(element)
+		cache.stats.Expirations++
+		return nil, false
+	}
+
+	cache.order.MoveToFront(element)
+	return cached, true
+}
+
+// add stores the value for the key from storedAt until expiresAt, replacing any previous one.
+func (cache *lru) add(key string, value interface{}, storedAt time.Time, expiresAt time.Time) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if element, found := cache.entries[key]; found {
+		element.Value = &entry{key: key, value: value, storedAt: storedAt, expiresAt: expiresAt}
+		cache.order.MoveToFront(element)
+		return
+	}
+
+	cache.entries[key] = cache.order.PushFront(&entry{key: key, value: value, storedAt: storedAt, expiresAt: expiresAt})
+	for cache.maxEntries > 0 && cache.order.Len() > cache.maxEntries {
+		cache.remove(cache.order.Back())
+		cache.stats.Evictions++
+	}
+}
+
+func (cache *lru) remove(element *list.Element) {
+	cache.order.Remove(element)
+	delete(cache.entries, element.Value.(*entry).key)
+}
+
+func (cache *lru) snapshot() Stats {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	stats := cache.stats
+	stats.Entries = cache.order.Len()
+	return stats
+}
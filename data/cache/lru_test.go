@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var noon = time.Date(2018, time.May, 5, 12, 0, 0, 0, time.UTC)
+
+func Test_lru_WHEN_Full_THEN_EvictLeastRecentlyUsed(t *testing.T) {
+	cache := newLRU(2, 0)
+	cache.add("a", 1, noon, noon.Add(time.Hour))
+	cache.add("b", 2, noon, noon.Add(time.Hour))
+	cache.get("a", noon)
+	cache.add("c", 3, noon, noon.Add(time.Hour))
+
+	_, _, foundA := cache.get("a", noon)
+	_, _, foundB := cache.get("b", noon)
+	_, _, foundC := cache.get("c", noon)
+
+	assert.True(t, foundA)
+	assert.False(t, foundB)
+	assert.True(t, foundC)
+	assert.Equal(t, Stats{Hits: 3, Misses: 1, Evictions: 1, Entries: 2}, cache.snapshot())
+}
+
+func Test_lru_WHEN_Expired_THEN_Miss(t *testing.T) {
+	cache := newLRU(0, 0)
+	cache.add("a", 1, noon, noon.Add(time.Minute))
+
+	value, storedAt, found := cache.get("a", noon)
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, noon, storedAt)
+
+	_, _, found = cache.get("a", noon.Add(2*time.Minute))
+	assert.False(t, found)
+	assert.Equal(t, Stats{Hits: 1, Misses: 1, Expirations: 1}, cache.snapshot())
+}
+
+func Test_lru_WHEN_AddExistingKey_THEN_Replace(t *testing.T) {
+	cache := newLRU(1, 0)
+	cache.add("a", 1, noon, noon.Add(time.Minute))
+	cache.add("a", 2, noon, noon.Add(time.Minute))
+
+	value, _, _ := cache.get("a", noon)
+	assert.Equal(t, 2, value)
+	assert.Equal(t, uint64(0), cache.snapshot().Evictions)
+}
+
+func Test_lru_WHEN_ExpiredButStillKept_THEN_OnlyGetStale(t *testing.T) {
+	cache := newLRU(0, time.Hour)
+	cache.add("a", 1, noon, noon.Add(time.Minute))
+
+	_, _, found := cache.get("a", noon.Add(30*time.Minute))
+	assert.False(t, found)
+	value, storedAt, found := cache.getStale("a", noon.Add(30*time.Minute))
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, noon, storedAt)
+
+	_, _, found = cache.getStale("a", noon.Add(2*time.Hour))
+	assert.False(t, found)
+	assert.Equal(t, Stats{Misses: 1, Expirations: 1, StaleHits: 1}, cache.snapshot())
+}
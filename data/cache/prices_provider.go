@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"org.alex859/stockprices/data/googlefinance"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase"
+)
+
+// Config tells how long prices are kept and how many of them.
+// Current prices change all the time during market hours, historical ones hardly ever, hence the separate TTLs.
+// When the provider fails, expired prices fetched up to MaxStaleness ago are served instead, marked as stale.
+type Config struct {
+	CurrentTTL    time.Duration
+	HistoricalTTL time.Duration
+	MaxEntries    int
+	MaxStaleness  time.Duration
+}
+
+// DefaultConfig suits a dashboard polling a watchlist every minute, showing last week's prices over a long weekend rather than nothing.
+var DefaultConfig = Config{CurrentTTL: time.Minute, HistoricalTTL: time.Hour, MaxEntries: 1000, MaxStaleness: 72 * time.Hour}
+
+// Keeps current prices returned by a provider for a while.
+// Prices come with their Freshness, stale ones are served only when the provider fails.
+type currentPriceCache struct {
+	provider usecase.CurrentPriceProvider
+	ttl      time.Duration
+	entries  *lru
+	now      func() time.Time
+}
+
+// NewCurrentPriceCache creates a cache for the current prices of the given provider, keeping up to maxEntries of them for ttl.
+// Expired prices fetched up to maxStaleness ago are served when the provider fails, zero meaning never.
+func NewCurrentPriceCache(provider usecase.CurrentPriceProvider, ttl time.Duration, maxEntries int, maxStaleness time.Duration) *currentPriceCache {
+	return &currentPriceCache{provider: provider, ttl: ttl, entries: newLRU(maxEntries, maxStaleness), now: time.Now}
+}
+
+func (cache *currentPriceCache) GetCurrentPrice(ctx context.Context, ticker entity.Ticker) (entity.CurrentPrice, error) {
+	key := ticker.String()
+	now := cache.now()
+	if cached, storedAt, found := cache.entries.get(key, now); found {
+		result := cached.(entity.CurrentPrice)
+		result.Freshness = entity.NewFreshness(storedAt, now, false)
+		return result, nil
+	}
+
+	result, err := cache.provider.GetCurrentPrice(ctx, ticker)
+	if err != nil {
+		if cached, storedAt, found := staleEntry(cache.entries, key, cache.now(), err); found {
+			result = cached.(entity.CurrentPrice)
+			result.Freshness = entity.NewFreshness(storedAt, cache.now(), true)
+			return result, nil
+		}
+		return result, err
+	}
+	if isStale(result.Freshness) {
+		return result, nil
+	}
+
+	fetchedAt := cache.now()
+	cache.entries.add(key, result, fetchedAt, fetchedAt.Add(cache.ttl))
+	result.Freshness = entity.NewFreshness(fetchedAt, fetchedAt, false)
+	return result, nil
+}
+
+// Stats tells how the cache has been used so far.
+func (cache *currentPriceCache) Stats() Stats {
+	return cache.entries.snapshot()
+}
+
+// Keeps price histories returned by a provider for a while.
+// Histories are stored by ticker and Google Finance period, the one FromDateInterval resolves the requested interval to,
+// so that requests with slightly different intervals share an entry.
+// On a miss, the history is fetched from the start of the requested interval up to now and filtered on the way out.
+// Histories come with their Freshness, stale ones are served only when the provider fails.
+type historicalPricesCache struct {
+	provider usecase.HistoricalPricesProvider
+	ttl      time.Duration
+	entries  *lru
+	now      func() time.Time
+}
+
+// A cached history along with the interval it covers, its prices kept in a series to filter them quickly on every hit.
+type cachedHistory struct {
+	history  entity.PriceHistory
+	series   entity.PriceSeries
+	interval entity.DateInterval
+}
+
+func newCachedHistory(history entity.PriceHistory, interval entity.DateInterval) cachedHistory {
+	return cachedHistory{history: history, series: history.Series(), interval: interval}
+}
+
+// NewHistoricalPricesCache creates a cache for the price histories of the given provider, keeping up to maxEntries of them for ttl.
+// Expired histories fetched up to maxStaleness ago are served when the provider fails, zero meaning never.
+func NewHistoricalPricesCache(provider usecase.HistoricalPricesProvider, ttl time.Duration, maxEntries int, maxStaleness time.Duration) *historicalPricesCache {
+	return &historicalPricesCache{provider: provider, ttl: ttl, entries: newLRU(maxEntries, maxStaleness), now: time.Now}
+}
+
+func (cache *historicalPricesCache) GetHistoricalPrices(ctx context.Context, ticker entity.Ticker, interval entity.DateInterval) (entity.PriceHistory, error) {
+	now := cache.now()
+	key := fmt.Sprintf("%s|%s", ticker, googlefinance.FromDateInterval(interval))
+	if cached, storedAt, found := cache.entries.get(key, now); found {
+		if cached := cached.(cachedHistory); cached.covers(interval) {
+			return cached.filtered(ticker, interval, entity.NewFreshness(storedAt, now, false))
+		}
+	}
+
+	fetchInterval := interval
+	if now.After(interval.To()) {
+		fetchInterval, _ = entity.NewDateInterval(interval.From(), now)
+	}
+	history, err := cache.provider.GetHistoricalPrices(ctx, ticker, fetchInterval)
+	if err != nil {
+		if cached, storedAt, found := staleEntry(cache.entries, key, cache.now(), err); found && cached.(cachedHistory).covers(interval) {
+			return cached.(cachedHistory).filtered(ticker, interval, entity.NewFreshness(storedAt, cache.now(), true))
+		}
+		return history, err
+	}
+	if isStale(history.Freshness) {
+		return newCachedHistory(history, fetchInterval).filtered(ticker, interval, history.Freshness)
+	}
+
+	fetchedAt := cache.now()
+	cached := newCachedHistory(history, fetchInterval)
+	cache.entries.add(key, cached, fetchedAt, fetchedAt.Add(cache.ttl))
+	return cached.filtered(ticker, interval, entity.NewFreshness(fetchedAt, fetchedAt, false))
+}
+
+// Stats tells how the cache has been used so far.
+func (cache *historicalPricesCache) Stats() Stats {
+	return cache.entries.snapshot()
+}
+
+// covers tells if the cached history has all the prices in the interval.
+func (cached cachedHistory) covers(interval entity.DateInterval) bool {
+	return !interval.From().Before(cached.interval.From())
+}
+
+// filtered returns a copy of the history with the prices in the interval only, ErrNothingFound when there is none.
+func (cached cachedHistory) filtered(ticker entity.Ticker, interval entity.DateInterval, freshness *entity.Freshness) (entity.PriceHistory, error) {
+	history := cached.history
+	calendar := history.Calendar()
+	history.Prices = cached.series.BetweenIn(interval, calendar).Prices()
+	if history.Bars != nil {
+		history.Bars = history.Bars.FilterIn(interval, calendar)
+	}
+	if len(history.Prices) == 0 {
+		return entity.PriceHistory{}, entity.NewErrNothingFound(ticker)
+	}
+	history.Freshness = freshness
+	return history, nil
+}
+
+// isStale tells if the provider itself served stale prices, which are passed on as they are rather than cached.
+func isStale(freshness *entity.Freshness) bool {
+	return freshness != nil && freshness.Stale
+}
+
+// staleEntry looks for an expired entry to serve in place of a failed fetch.
+// A ticker the provider knows nothing about gets no stale prices, they would hide the problem for good.
+func staleEntry(entries *lru, key string, now time.Time, err error) (interface{}, time.Time, bool) {
+	if _, nothingFound := errors.Cause(err).(entity.ErrNothingFound); nothingFound {
+		return nil, time.Time{}, false
+	}
+	return entries.getStale(key, now)
+}
+
+// Caches both current prices and price histories of a provider.
+type pricesProviderCache struct {
+	*currentPriceCache
+	*historicalPricesCache
+}
+
+// NewPricesProviderCache creates caches for both kinds of prices of the given provider.
+func NewPricesProviderCache(provider usecase.PricesProvider, config Config) *pricesProviderCache {
+	return &pricesProviderCache{
+		currentPriceCache:     NewCurrentPriceCache(provider, config.CurrentTTL, config.MaxEntries, config.MaxStaleness),
+		historicalPricesCache: NewHistoricalPricesCache(provider, config.HistoricalTTL, config.MaxEntries, config.MaxStaleness),
+	}
+}
+
+// Stats tells how both caches have been used so far.
+func (cache *pricesProviderCache) Stats() (current Stats, historical Stats) {
+	return cache.currentPriceCache.Stats(), cache.historicalPricesCache.Stats()
+}
@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase/mocks"
+)
+
+var anp = entity.Ticker{Market: "LON", Symbol: "ANP"}
+
+func Test_GetCurrentPrice_WHEN_Cached_THEN_DoNotAskProviderAgain(t *testing.T) {
+	provider := &mocks.CurrentPriceProvider{}
+	provider.On("GetCurrentPrice", mock.Anything, anp).Return(entity.CurrentPrice{Price: 12.5}, nil).Once()
+	cache := NewCurrentPriceCache(provider, time.Minute, 10, 0)
+
+	for i := 0; i < 3; i++ {
+		result, err := cache.GetCurrentPrice(context.Background(), anp)
+		assert.NoError(t, err)
+		assert.Equal(t, 12.5, result.Price)
+	}
+
+	provider.AssertNumberOfCalls(t, "GetCurrentPrice", 1)
+	assert.Equal(t, Stats{Hits: 2, Misses: 1, Entries: 1}, cache.Stats())
+}
+
+func Test_GetCurrentPrice_WHEN_Expired_THEN_AskProviderAgain(t *testing.T) {
+	provider := &mocks.CurrentPriceProvider{}
+	provider.On("GetCurrentPrice", mock.Anything, anp).Return(entity.CurrentPrice{Price: 12.5}, nil)
+	cache := NewCurrentPriceCache(provider, time.Minute, 10, 0)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.GetCurrentPrice(context.Background(), anp)
+	now = now.Add(2 * time.Minute)
+	cache.GetCurrentPrice(context.Background(), anp)
+
+	provider.AssertNumberOfCalls(t, "GetCurrentPrice", 2)
+}
+
+func Test_GetCurrentPrice_WHEN_ProviderFails_THEN_DoNotCache(t *testing.T) {
+	provider := &mocks.CurrentPriceProvider{}
+	provider.On("GetCurrentPrice", mock.Anything, anp).Return(entity.CurrentPrice{}, errors.New("can't talk to server"))
+	cache := NewCurrentPriceCache(provider, time.Minute, 10, 0)
+
+	_, err1 := cache.GetCurrentPrice(context.Background(), anp)
+	_, err2 := cache.GetCurrentPrice(context.Background(), anp)
+
+	assert.Error(t, err1)
+	assert.Error(t, err2)
+	provider.AssertNumberOfCalls(t, "GetCurrentPrice", 2)
+}
+
+func Test_GetHistoricalPrices_WHEN_LaterStartInSamePeriod_THEN_ServeFromCache(t *testing.T) {
+	now := time.Now()
+	prices := entity.PriceList{
+		{Price: 10, Time: now.AddDate(0, 0, -20)},
+		{Price: 11, Time: now.AddDate(0, 0, -15)},
+		{Price: 12, Time: now.AddDate(0, 0, -10)},
+	}
+	provider := &mocks.HistoricalPricesProvider{}
+	provider.On("GetHistoricalPrices", mock.Anything, anp, mock.Anything).Return(entity.PriceHistory{Prices: prices}, nil)
+	cache := NewHistoricalPricesCache(provider, time.Hour, 10, 0)
+	cache.now = func() time.Time { return now }
+
+	first, _ := entity.NewDateInterval(now.AddDate(0, 0, -21), now.AddDate(0, 0, -12))
+	second, _ := entity.NewDateInterval(now.AddDate(0, 0, -16), now)
+
+	result, err := cache.GetHistoricalPrices(context.Background(), anp, first)
+	if assert.NoError(t, err) {
+		assert.Equal(t, prices[:2], result.Prices)
+	}
+	result, err = cache.GetHistoricalPrices(context.Background(), anp, second)
+	if assert.NoError(t, err) {
+		assert.Equal(t, prices[1:], result.Prices)
+	}
+
+	provider.AssertNumberOfCalls(t, "GetHistoricalPrices", 1)
+	fetched := provider.Calls[0].Arguments.Get(2).(entity.DateInterval)
+	assert.Equal(t, now, fetched.To())
+}
+
+func Test_GetHistoricalPrices_WHEN_EarlierStart_THEN_AskProviderAgain(t *testing.T) {
+	now := time.Now()
+	provider := &mocks.HistoricalPricesProvider{}
+	provider.On("GetHistoricalPrices", mock.Anything, anp, mock.Anything).Return(entity.PriceHistory{Prices: entity.PriceList{{Price: 10, Time: now.AddDate(0, 0, -1)}}}, nil)
+	cache := NewHistoricalPricesCache(provider, time.Hour, 10, 0)
+	cache.now = func() time.Time { return now }
+
+	later, _ := entity.NewDateInterval(now.AddDate(0, 0, -10), now)
+	earlier, _ := entity.NewDateInterval(now.AddDate(0, 0, -20), now)
+	cache.GetHistoricalPrices(context.Background(), anp, later)
+	cache.GetHistoricalPrices(context.Background(), anp, earlier)
+
+	provider.AssertNumberOfCalls(t, "GetHistoricalPrices", 2)
+}
+
+func Test_GetCurrentPrice_WHEN_ProviderFailsAfterExpiry_THEN_ServeStale(t *testing.T) {
+	provider := &mocks.CurrentPriceProvider{}
+	provider.On("GetCurrentPrice", mock.Anything, anp).Return(entity.CurrentPrice{Price: 12.5}, nil).Once()
+	provider.On("GetCurrentPrice", mock.Anything, anp).Return(entity.CurrentPrice{}, errors.New("can't talk to server"))
+	cache := NewCurrentPriceCache(provider, time.Minute, 10, time.Hour)
+	now := time.Date(2018, time.May, 5, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	result, err := cache.GetCurrentPrice(context.Background(), anp)
+	if assert.NoError(t, err) {
+		assert.Equal(t, &entity.Freshness{FetchedAt: now}, result.Freshness)
+	}
+
+	fetchedAt := now
+	now = now.Add(30 * time.Minute)
+	result, err = cache.GetCurrentPrice(context.Background(), anp)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 12.5, result.Price)
+		assert.Equal(t, &entity.Freshness{FetchedAt: fetchedAt, Age: 30 * time.Minute, Stale: true}, result.Freshness)
+	}
+
+	now = now.Add(time.Hour)
+	_, err = cache.GetCurrentPrice(context.Background(), anp)
+	assert.Error(t, err, "prices older than the max staleness should not be served")
+}
+
+func Test_GetCurrentPrice_WHEN_NothingFoundAfterExpiry_THEN_DoNotServeStale(t *testing.T) {
+	provider := &mocks.CurrentPriceProvider{}
+	provider.On("GetCurrentPrice", mock.Anything, anp).Return(entity.CurrentPrice{Price: 12.5}, nil).Once()
+	provider.On("GetCurrentPrice", mock.Anything, anp).Return(entity.CurrentPrice{}, entity.NewErrNothingFound(anp))
+	cache := NewCurrentPriceCache(provider, time.Minute, 10, time.Hour)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.GetCurrentPrice(context.Background(), anp)
+	now = now.Add(2 * time.Minute)
+	_, err := cache.GetCurrentPrice(context.Background(), anp)
+
+	assert.IsType(t, entity.ErrNothingFound{}, err)
+}
+
+func Test_GetHistoricalPrices_WHEN_ProviderFailsAfterExpiry_THEN_ServeStale(t *testing.T) {
+	now := time.Now()
+	prices := entity.PriceList{{Price: 10, Time: now.AddDate(0, 0, -10)}}
+	provider := &mocks.HistoricalPricesProvider{}
+	provider.On("GetHistoricalPrices", mock.Anything, anp, mock.Anything).Return(entity.PriceHistory{Prices: prices}, nil).Once()
+	provider.On("GetHistoricalPrices", mock.Anything, anp, mock.Anything).Return(entity.PriceHistory{}, context.DeadlineExceeded)
+	cache := NewHistoricalPricesCache(provider, time.Hour, 10, 24*time.Hour)
+	cache.now = func() time.Time { return now }
+	interval, _ := entity.NewDateInterval(now.AddDate(0, 0, -20), now)
+
+	cache.GetHistoricalPrices(context.Background(), anp, interval)
+	now = now.Add(2 * time.Hour)
+	result, err := cache.GetHistoricalPrices(context.Background(), anp, interval)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, prices, result.Prices)
+		assert.True(t, result.Freshness.Stale)
+		assert.Equal(t, 2*time.Hour, result.Freshness.Age)
+	}
+}
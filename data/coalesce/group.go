@@ -0,0 +1,52 @@
+package coalesce
+
+import (
+	"context"
+	"sync"
+)
+
+// An upstream call in flight, or done.
+type call struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// Runs a single call at a time for each key, the callers asking for a key already in flight wait for its result.
+type group struct {
+	mutex sync.Mutex
+	calls map[string]*call
+}
+
+func newGroup() *group {
+	return &group{calls: map[string]*call{}}
+}
+
+// do runs fn, unless a call with the same key is already in flight, in which case it waits for its result instead.
+// shared tells if the result comes from another caller's call. Waiting stops when the context is done.
+func (g *group) do(ctx context.Context, key string, fn func() (interface{}, error)) (value interface{}, err error, shared bool) {
+	g.mutex.Lock()
+	if inFlight, found := g.calls[key]; found {
+		g.mutex.Unlock()
+		select {
+		case <-inFlight.done:
+			return inFlight.value, inFlight.err, true
+		case <-ctx.Done():
+			return nil, ctx.Err(), true
+		}
+	}
+
+	c := &call{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mutex.Unlock()
+
+	defer func() {
+		g.mutex.Lock()
+		delete(g.calls, key)
+		g.mutex.Unlock()
+		close(c.done)
+	}()
+
+	c.value, c.err = fn()
+	return c.value, c.err, false
+}
@@ -0,0 +1,92 @@
+package coalesce
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"org.alex859/stockprices/data/googlefinance"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase"
+)
+
+// Shares a single call to the provider between the concurrent requests for the current price of the same ticker.
+type currentPriceCoalescer struct {
+	provider usecase.CurrentPriceProvider
+	calls    *group
+}
+
+// NewCurrentPriceCoalescer creates a provider coalescing the concurrent requests to the given one.
+func NewCurrentPriceCoalescer(provider usecase.CurrentPriceProvider) *currentPriceCoalescer {
+	return &currentPriceCoalescer{provider: provider, calls: newGroup()}
+}
+
+func (coalescer *currentPriceCoalescer) GetCurrentPrice(ctx context.Context, ticker entity.Ticker) (entity.CurrentPrice, error) {
+	value, err, shared := coalescer.calls.do(ctx, ticker.String(), func() (interface{}, error) {
+		return coalescer.provider.GetCurrentPrice(ctx, ticker)
+	})
+	if shared && gaveUp(err) && ctx.Err() == nil {
+		return coalescer.provider.GetCurrentPrice(ctx, ticker)
+	}
+	if value == nil {
+		return entity.CurrentPrice{}, err
+	}
+
+	return value.(entity.CurrentPrice), err
+}
+
+// Shares a single call to the provider between the concurrent requests for the history of the same ticker.
+// Requests share a call when they resolve to the same Google Finance period and cover the same days.
+type historicalPricesCoalescer struct {
+	provider usecase.HistoricalPricesProvider
+	calls    *group
+}
+
+// NewHistoricalPricesCoalescer creates a provider coalescing the concurrent requests to the given one.
+func NewHistoricalPricesCoalescer(provider usecase.HistoricalPricesProvider) *historicalPricesCoalescer {
+	return &historicalPricesCoalescer{provider: provider, calls: newGroup()}
+}
+
+func (coalescer *historicalPricesCoalescer) GetHistoricalPrices(ctx context.Context, ticker entity.Ticker, interval entity.DateInterval) (entity.PriceHistory, error) {
+	const dayLayout = "2006-01-02"
+	key := fmt.Sprintf("%s|%s|%s|%s", ticker, googlefinance.FromDateInterval(interval), interval.From().Format(dayLayout), interval.To().Format(dayLayout))
+	value, err, shared := coalescer.calls.do(ctx, key, func() (interface{}, error) {
+		return coalescer.provider.GetHistoricalPrices(ctx, ticker, interval)
+	})
+	if shared && gaveUp(err) && ctx.Err() == nil {
+		return coalescer.provider.GetHistoricalPrices(ctx, ticker, interval)
+	}
+	if value == nil {
+		return entity.PriceHistory{}, err
+	}
+
+	history := value.(entity.PriceHistory)
+	if shared {
+		// Callers own the prices they get and are free to change them.
+		history.Prices = append(entity.PriceList(nil), history.Prices...)
+		if history.Bars != nil {
+			history.Bars = append(entity.BarList(nil), history.Bars...)
+		}
+	}
+	return history, err
+}
+
+// gaveUp tells if a call failed because its caller gave up on it, in which case the callers sharing it should try on their own.
+func gaveUp(err error) bool {
+	cause := errors.Cause(err)
+	return cause == context.Canceled || cause == context.DeadlineExceeded
+}
+
+// Coalesces the requests for both current prices and price histories.
+type pricesProviderCoalescer struct {
+	*currentPriceCoalescer
+	*historicalPricesCoalescer
+}
+
+// NewPricesProviderCoalescer creates a provider coalescing the concurrent requests of both kinds to the given one.
+func NewPricesProviderCoalescer(provider usecase.PricesProvider) *pricesProviderCoalescer {
+	return &pricesProviderCoalescer{
+		currentPriceCoalescer:     NewCurrentPriceCoalescer(provider),
+		historicalPricesCoalescer: NewHistoricalPricesCoalescer(provider),
+	}
+}
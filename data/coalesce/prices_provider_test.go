@@ -0,0 +1,130 @@
+package coalesce
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase/mocks"
+)
+
+var anp = entity.Ticker{Market: "LON", Symbol: "ANP"}
+
+// concurrently runs fn n times at once and waits for all of them to be done.
+func concurrently(n int, fn func()) {
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+	}
+	wg.Wait()
+}
+
+func Test_GetCurrentPrice_WHEN_ConcurrentRequests_THEN_ShareOneCall(t *testing.T) {
+	release := make(chan struct{})
+	provider := &mocks.CurrentPriceProvider{}
+	provider.On("GetCurrentPrice", mock.Anything, anp).Return(func(ctx context.Context, ticker entity.Ticker) entity.CurrentPrice {
+		<-release
+		return entity.CurrentPrice{Price: 12.5}
+	}, nil)
+	coalescer := NewCurrentPriceCoalescer(provider)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+	concurrently(5, func() {
+		result, err := coalescer.GetCurrentPrice(context.Background(), anp)
+		assert.NoError(t, err)
+		assert.Equal(t, 12.5, result.Price)
+	})
+
+	provider.AssertNumberOfCalls(t, "GetCurrentPrice", 1)
+}
+
+func Test_GetCurrentPrice_WHEN_SequentialRequests_THEN_CallEachTime(t *testing.T) {
+	provider := &mocks.CurrentPriceProvider{}
+	provider.On("GetCurrentPrice", mock.Anything, anp).Return(entity.CurrentPrice{Price: 12.5}, nil)
+	coalescer := NewCurrentPriceCoalescer(provider)
+
+	coalescer.GetCurrentPrice(context.Background(), anp)
+	coalescer.GetCurrentPrice(context.Background(), anp)
+
+	provider.AssertNumberOfCalls(t, "GetCurrentPrice", 2)
+}
+
+func Test_GetHistoricalPrices_WHEN_ConcurrentRequests_THEN_ShareOneCallAndCopyPrices(t *testing.T) {
+	release := make(chan struct{})
+	interval, _ := entity.NewDateInterval(time.Now().AddDate(0, -2, 0), time.Now())
+	prices := entity.PriceList{{Price: 10, Time: time.Now().AddDate(0, -1, 0)}}
+	provider := &mocks.HistoricalPricesProvider{}
+	provider.On("GetHistoricalPrices", mock.Anything, anp, interval).Return(func(ctx context.Context, ticker entity.Ticker, interval entity.DateInterval) entity.PriceHistory {
+		<-release
+		return entity.PriceHistory{Prices: prices}
+	}, nil)
+	coalescer := NewHistoricalPricesCoalescer(provider)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+	var mutex sync.Mutex
+	var results []entity.PriceList
+	concurrently(5, func() {
+		result, err := coalescer.GetHistoricalPrices(context.Background(), anp, interval)
+		assert.NoError(t, err)
+		mutex.Lock()
+		results = append(results, result.Prices)
+		mutex.Unlock()
+	})
+
+	provider.AssertNumberOfCalls(t, "GetHistoricalPrices", 1)
+	for _, result := range results {
+		assert.Equal(t, prices, result)
+	}
+	shared := 0
+	for _, result := range results {
+		if &result[0] != &prices[0] {
+			shared++
+		}
+	}
+	assert.Equal(t, 4, shared)
+}
+
+func Test_GetCurrentPrice_WHEN_LeaderGivesUp_THEN_FollowerTriesOnItsOwn(t *testing.T) {
+	started := make(chan struct{})
+	calls := 0
+	provider := &mocks.CurrentPriceProvider{}
+	provider.On("GetCurrentPrice", mock.Anything, anp).Return(func(ctx context.Context, ticker entity.Ticker) entity.CurrentPrice {
+		calls++
+		if calls == 1 {
+			close(started)
+			<-ctx.Done()
+			return entity.CurrentPrice{}
+		}
+		return entity.CurrentPrice{Price: 12.5}
+	}, func(ctx context.Context, ticker entity.Ticker) error {
+		return ctx.Err()
+	})
+	coalescer := NewCurrentPriceCoalescer(provider)
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	go coalescer.GetCurrentPrice(leaderCtx, anp)
+	<-started
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	result, err := coalescer.GetCurrentPrice(context.Background(), anp)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 12.5, result.Price)
+	}
+}
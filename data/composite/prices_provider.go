@@ -0,0 +1,107 @@
+package composite
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/pkg/errors"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase"
+)
+
+// Source is a named price provider taking part in a fallback chain.
+// Current or Historical can be left nil when the source does not support that kind of query.
+type Source struct {
+	Name       string
+	Current    usecase.CurrentPriceProvider
+	Historical usecase.HistoricalPricesProvider
+}
+
+// Tries an ordered list of sources for each ticker, returning the first answer.
+type compositePricesProvider struct {
+	sources            []Source
+	stopOnNothingFound bool
+}
+
+// NewCompositePricesProvider creates a provider trying the given sources in order.
+// When stopOnNothingFound is true, an ErrNothingFound from a source ends the chain instead of falling through to the next one.
+func NewCompositePricesProvider(stopOnNothingFound bool, sources ...Source) *compositePricesProvider {
+	return &compositePricesProvider{sources: sources, stopOnNothingFound: stopOnNothingFound}
+}
+
+func (provider *compositePricesProvider) GetCurrentPrice(ctx context.Context, ticker entity.Ticker) (result entity.CurrentPrice, err error) {
+	failures := &chainFailures{ticker: ticker}
+	for _, source := range provider.sources {
+		if source.Current == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return entity.CurrentPrice{}, ctx.Err()
+		}
+
+		if result, err = source.Current.GetCurrentPrice(ctx, ticker); err == nil {
+			result.Source = source.Name
+			return result, nil
+		}
+
+		if failures.add(source.Name, err) && provider.stopOnNothingFound {
+			break
+		}
+	}
+
+	return entity.CurrentPrice{}, failures.err()
+}
+
+func (provider *compositePricesProvider) GetHistoricalPrices(ctx context.Context, ticker entity.Ticker, interval entity.DateInterval) (result entity.PriceHistory, err error) {
+	failures := &chainFailures{ticker: ticker}
+	for _, source := range provider.sources {
+		if source.Historical == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return entity.PriceHistory{}, ctx.Err()
+		}
+
+		if result, err = source.Historical.GetHistoricalPrices(ctx, ticker, interval); err == nil {
+			result.Source = source.Name
+			return result, nil
+		}
+
+		if failures.add(source.Name, err) && provider.stopOnNothingFound {
+			break
+		}
+	}
+
+	return entity.PriceHistory{}, failures.err()
+}
+
+// Collects the errors returned by the sources of a chain for a single ticker.
+type chainFailures struct {
+	ticker      entity.Ticker
+	messages    []string
+	lastFailure error
+}
+
+// add records a source error and reports whether it was an ErrNothingFound.
+func (failures *chainFailures) add(sourceName string, err error) bool {
+	log.Printf("Price source %s failed for ticker: %s. Error: %+v", sourceName, failures.ticker, err)
+	failures.messages = append(failures.messages, fmt.Sprintf("%s: %s", sourceName, err))
+
+	if _, nothingFound := errors.Cause(err).(entity.ErrNothingFound); nothingFound {
+		return true
+	}
+
+	failures.lastFailure = err
+	return false
+}
+
+// err returns ErrNothingFound if no source had a failure other than nothing found, the last failure otherwise.
+func (failures *chainFailures) err() error {
+	if failures.lastFailure == nil {
+		return entity.NewErrNothingFound(failures.ticker)
+	}
+
+	return errors.Wrapf(failures.lastFailure, "no price source could answer for ticker %s (%s)", failures.ticker, strings.Join(failures.messages, "; "))
+}
@@ -0,0 +1,120 @@
+package composite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase/mocks"
+)
+
+var ticker = entity.Ticker{Market: "LON", Symbol: "ANP"}
+var tickerInfo = entity.TickerInfo{Name: "Anpario", Ticker: ticker, Currency: "GBX"}
+var may5, _ = time.Parse("02-01-2006", "05-05-2018")
+var may9, _ = time.Parse("02-01-2006", "09-05-2018")
+
+func Test_GetCurrentPrice_WHEN_FirstSourceAnswers_THEN_DoNotAskOthers(t *testing.T) {
+	first := &mocks.CurrentPriceProvider{}
+	second := &mocks.CurrentPriceProvider{}
+	first.On("GetCurrentPrice", mock.Anything, ticker).Return(entity.CurrentPrice{TickerInfo: tickerInfo, Price: 12.5}, nil)
+
+	provider := NewCompositePricesProvider(false, Source{Name: "first", Current: first}, Source{Name: "second", Current: second})
+
+	if result, err := provider.GetCurrentPrice(context.Background(), ticker); assert.NoError(t, err) {
+		assert.Equal(t, entity.CurrentPrice{TickerInfo: tickerInfo, Price: 12.5, Source: "first"}, result)
+	}
+	second.AssertNotCalled(t, "GetCurrentPrice", mock.Anything, mock.Anything)
+}
+
+func Test_GetCurrentPrice_WHEN_FirstSourceFails_THEN_FallBackToNext(t *testing.T) {
+	first := &mocks.CurrentPriceProvider{}
+	second := &mocks.CurrentPriceProvider{}
+	first.On("GetCurrentPrice", mock.Anything, ticker).Return(entity.CurrentPrice{}, errors.New("can't talk to server"))
+	second.On("GetCurrentPrice", mock.Anything, ticker).Return(entity.CurrentPrice{TickerInfo: tickerInfo, Price: 12.5}, nil)
+
+	provider := NewCompositePricesProvider(false, Source{Name: "first", Current: first}, Source{Name: "second", Current: second})
+
+	if result, err := provider.GetCurrentPrice(context.Background(), ticker); assert.NoError(t, err) {
+		assert.Equal(t, "second", result.Source)
+	}
+}
+
+func Test_GetCurrentPrice_WHEN_SourceDoesNotSupportCurrentPrices_THEN_SkipIt(t *testing.T) {
+	historical := &mocks.HistoricalPricesProvider{}
+	current := &mocks.CurrentPriceProvider{}
+	current.On("GetCurrentPrice", mock.Anything, ticker).Return(entity.CurrentPrice{TickerInfo: tickerInfo}, nil)
+
+	provider := NewCompositePricesProvider(false, Source{Name: "csv", Historical: historical}, Source{Name: "google", Current: current})
+
+	if result, err := provider.GetCurrentPrice(context.Background(), ticker); assert.NoError(t, err) {
+		assert.Equal(t, "google", result.Source)
+	}
+}
+
+func Test_GetCurrentPrice_WHEN_AllSourcesFail_THEN_ReturnLastFailure(t *testing.T) {
+	first := &mocks.CurrentPriceProvider{}
+	second := &mocks.CurrentPriceProvider{}
+	first.On("GetCurrentPrice", mock.Anything, ticker).Return(entity.CurrentPrice{}, errors.New("can't talk to server"))
+	second.On("GetCurrentPrice", mock.Anything, ticker).Return(entity.CurrentPrice{}, entity.NewErrNothingFound(ticker))
+
+	provider := NewCompositePricesProvider(false, Source{Name: "first", Current: first}, Source{Name: "second", Current: second})
+	_, err := provider.GetCurrentPrice(context.Background(), ticker)
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "can't talk to server")
+		assert.Contains(t, err.Error(), "second")
+	}
+}
+
+func Test_GetHistoricalPrices_WHEN_NothingFoundAndNotStopping_THEN_FallBackToNext(t *testing.T) {
+	interval, _ := entity.NewDateInterval(may5, may9)
+	first := &mocks.HistoricalPricesProvider{}
+	second := &mocks.HistoricalPricesProvider{}
+	first.On("GetHistoricalPrices", mock.Anything, ticker, interval).Return(entity.PriceHistory{}, entity.NewErrNothingFound(ticker))
+	second.On("GetHistoricalPrices", mock.Anything, ticker, interval).Return(entity.PriceHistory{TickerInfo: tickerInfo}, nil)
+
+	provider := NewCompositePricesProvider(false, Source{Name: "first", Historical: first}, Source{Name: "second", Historical: second})
+
+	if result, err := provider.GetHistoricalPrices(context.Background(), ticker, interval); assert.NoError(t, err) {
+		assert.Equal(t, entity.PriceHistory{TickerInfo: tickerInfo, Source: "second"}, result)
+	}
+}
+
+func Test_GetHistoricalPrices_WHEN_NothingFoundAndStopping_THEN_ReturnNothingFound(t *testing.T) {
+	interval, _ := entity.NewDateInterval(may5, may9)
+	first := &mocks.HistoricalPricesProvider{}
+	second := &mocks.HistoricalPricesProvider{}
+	first.On("GetHistoricalPrices", mock.Anything, ticker, interval).Return(entity.PriceHistory{}, entity.NewErrNothingFound(ticker))
+
+	provider := NewCompositePricesProvider(true, Source{Name: "first", Historical: first}, Source{Name: "second", Historical: second})
+	_, err := provider.GetHistoricalPrices(context.Background(), ticker, interval)
+
+	assert.IsType(t, entity.ErrNothingFound{}, err)
+	second.AssertNotCalled(t, "GetHistoricalPrices", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_GetHistoricalPrices_WHEN_TransportFailureAndStopping_THEN_FallBackToNext(t *testing.T) {
+	interval, _ := entity.NewDateInterval(may5, may9)
+	first := &mocks.HistoricalPricesProvider{}
+	second := &mocks.HistoricalPricesProvider{}
+	first.On("GetHistoricalPrices", mock.Anything, ticker, interval).Return(entity.PriceHistory{}, errors.New("can't talk to server"))
+	second.On("GetHistoricalPrices", mock.Anything, ticker, interval).Return(entity.PriceHistory{TickerInfo: tickerInfo}, nil)
+
+	provider := NewCompositePricesProvider(true, Source{Name: "first", Historical: first}, Source{Name: "second", Historical: second})
+
+	if result, err := provider.GetHistoricalPrices(context.Background(), ticker, interval); assert.NoError(t, err) {
+		assert.Equal(t, "second", result.Source)
+	}
+}
+
+func Test_GetHistoricalPrices_WHEN_NoSources_THEN_ReturnNothingFound(t *testing.T) {
+	interval, _ := entity.NewDateInterval(may5, may9)
+	provider := NewCompositePricesProvider(false)
+	_, err := provider.GetHistoricalPrices(context.Background(), ticker, interval)
+
+	assert.IsType(t, entity.ErrNothingFound{}, err)
+}
@@ -0,0 +1,340 @@
+package csvfile
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"org.alex859/stockprices/domain/entity"
+)
+
+type (
+	// Config defines where the CSV files live and how to read them.
+	Config struct {
+		// Dir is the directory containing one file per ticker.
+		Dir string
+		// FileName maps a ticker to its file name in Dir. Defaults to MARKET_SYMBOL.csv.
+		FileName func(ticker entity.Ticker) string
+		// DateLayouts are tried in order when parsing the date column. Defaults to DefaultDateLayouts.
+		DateLayouts []string
+		// Location is used for dates not carrying a time zone. Defaults to the exchange time zone of the ticker market.
+		Location *time.Location
+		// Columns names the header columns to read. Defaults to DefaultColumns.
+		Columns Columns
+		// Comma is the field delimiter. Defaults to ','.
+		Comma rune
+		// DecimalSeparator is either '.', the default, or ','. The other one is read as a thousands separator, in numbers
+		// grouping digits by three only, so that e.g. 80,25 is refused rather than read as 8025.
+		DecimalSeparator rune
+	}
+
+	// Columns maps the fields we know about to header names, matched ignoring case.
+	// Date and Close are required, the others are read when present in the file.
+	Columns struct {
+		Date   string
+		Open   string
+		High   string
+		Low    string
+		Close  string
+		Volume string
+	}
+
+	// A single parsed line of a CSV file, along with which of the optional prices it has.
+	row struct {
+		time                     time.Time
+		open                     float64
+		high                     float64
+		low                      float64
+		close                    float64
+		volume                   float64
+		hasOpen, hasHigh, hasLow bool
+	}
+
+	// Position of each known column in a file, -1 when missing.
+	columnIndexes struct {
+		date, open, high, low, close, volume int
+	}
+)
+
+// DefaultDateLayouts are the date layouts used when none are configured.
+var DefaultDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"02-01-2006",
+}
+
+// DefaultColumns are the header names used when none are configured.
+var DefaultColumns = Columns{Date: "Date", Open: "Open", High: "High", Low: "Low", Close: "Close", Volume: "Volume"}
+
+// Reads end of day prices from CSV files on the local file system.
+type csvFilePricesProvider struct {
+	config Config
+}
+
+// NewCSVFilePricesProvider creates a new CSV file prices provider, filling in defaults for missing config values.
+func NewCSVFilePricesProvider(config Config) *csvFilePricesProvider {
+	if config.FileName == nil {
+		config.FileName = DefaultFileName
+	}
+	if len(config.DateLayouts) == 0 {
+		config.DateLayouts = DefaultDateLayouts
+	}
+	if config.Columns == (Columns{}) {
+		config.Columns = DefaultColumns
+	}
+	if config.Comma == 0 {
+		config.Comma = ','
+	}
+	if config.DecimalSeparator == 0 {
+		config.DecimalSeparator = '.'
+	}
+	return &csvFilePricesProvider{config: config}
+}
+
+// DefaultFileName maps LON:ANP to LON_ANP.csv, replacing separators and dots that could point outside of Dir.
+func DefaultFileName(ticker entity.Ticker) string {
+	safe := strings.NewReplacer("/", "_", "\\", "_", "..", "_")
+	return safe.Replace(fmt.Sprintf("%s_%s.csv", ticker.Market, ticker.Symbol))
+}
+
+// GetHistoricalPrices returns the prices in the ticker file falling in the interval.
+// An ErrNothingFound is returned when there is no file for the ticker or no price in the interval.
+func (provider *csvFilePricesProvider) GetHistoricalPrices(ctx context.Context, ticker entity.Ticker, interval entity.DateInterval) (entity.PriceHistory, error) {
+	if ctx.Err() != nil {
+		return entity.PriceHistory{}, ctx.Err()
+	}
+
+	rows, indexes, err := provider.readRows(ticker)
+	if err != nil {
+		return entity.PriceHistory{}, err
+	}
+
+	result := entity.PriceHistory{TickerInfo: entity.TickerInfo{Ticker: ticker}, Prices: make(entity.PriceList, len(rows))}
+	for i, r := range rows {
+		result.Prices[i] = entity.PricePoint{Price: r.close, Time: r.time}
+	}
+	if indexes.hasBars() {
+		result.Bars = make(entity.BarList, len(rows))
+		for i, r := range rows {
+			result.Bars[i] = r.bar()
+		}
+	}
+
+	result = result.FilterByInterval(interval)
+	if len(result.Prices) == 0 {
+		return entity.PriceHistory{}, entity.NewErrNothingFound(ticker)
+	}
+	return result, nil
+}
+
+// readRows reads all the rows in the ticker file, chronologically ordered, along with the columns found.
+func (provider *csvFilePricesProvider) readRows(ticker entity.Ticker) ([]row, columnIndexes, error) {
+	fileName, err := provider.fileName(ticker)
+	if err != nil {
+		return nil, columnIndexes{}, err
+	}
+	file, err := os.Open(fileName)
+	if os.IsNotExist(err) {
+		return nil, columnIndexes{}, entity.NewErrNothingFound(ticker)
+	}
+	if err != nil {
+		return nil, columnIndexes{}, errors.Wrap(err, "unable to open prices file")
+	}
+	defer file.Close()
+
+	rows, indexes, err := provider.parse(file, provider.location(ticker))
+	if err != nil {
+		return nil, indexes, errors.Wrapf(entity.NewErrMalformedData("csvfile", err.Error()), "unable to read prices file for ticker %s", ticker)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].time.Before(rows[j].time)
+	})
+	return rows, indexes, nil
+}
+
+// fileName returns the path of the ticker file, refusing the ones a custom FileName puts outside of Dir.
+func (provider *csvFilePricesProvider) fileName(ticker entity.Ticker) (string, error) {
+	result := filepath.Join(provider.config.Dir, provider.config.FileName(ticker))
+	relative, err := filepath.Rel(provider.config.Dir, result)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", entity.NewErrValidation(fmt.Sprintf("Invalid ticker %s", ticker))
+	}
+	return result, nil
+}
+
+func (provider *csvFilePricesProvider) parse(reader io.Reader, location *time.Location) ([]row, columnIndexes, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma = provider.config.Comma
+	csvReader.TrimLeadingSpace = true
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, columnIndexes{}, errors.New("empty file")
+	}
+	if err != nil {
+		return nil, columnIndexes{}, errors.Wrap(err, "unable to read header")
+	}
+
+	indexes, err := provider.columnIndexes(header)
+	if err != nil {
+		return nil, indexes, err
+	}
+
+	var rows []row
+	for line := 2; ; line++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return rows, indexes, nil
+		}
+		if err != nil {
+			return nil, indexes, errors.Wrapf(err, "unable to read line %d", line)
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		r, err := provider.parseRecord(record, indexes, location)
+		if err != nil {
+			return nil, indexes, errors.Wrapf(err, "invalid line %d", line)
+		}
+		rows = append(rows, r)
+	}
+}
+
+func (provider *csvFilePricesProvider) columnIndexes(header []string) (columnIndexes, error) {
+	find := func(name string) int {
+		for i, column := range header {
+			if name != "" && strings.EqualFold(strings.TrimSpace(column), name) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	columns := provider.config.Columns
+	indexes := columnIndexes{
+		date:   find(columns.Date),
+		open:   find(columns.Open),
+		high:   find(columns.High),
+		low:    find(columns.Low),
+		close:  find(columns.Close),
+		volume: find(columns.Volume),
+	}
+
+	if indexes.date < 0 {
+		return indexes, errors.Errorf("missing date column %q", columns.Date)
+	}
+	if indexes.close < 0 {
+		return indexes, errors.Errorf("missing close column %q", columns.Close)
+	}
+	return indexes, nil
+}
+
+// hasBars tells if the file has the columns needed to build bars, volume being optional.
+func (indexes columnIndexes) hasBars() bool {
+	return indexes.open >= 0 && indexes.high >= 0 && indexes.low >= 0
+}
+
+// bar builds the Bar of the row, taking the close price for empty open, high or low cells.
+func (r row) bar() entity.Bar {
+	result := entity.Bar{Time: r.time, Open: r.open, High: r.high, Low: r.low, Close: r.close, Volume: r.volume}
+	for _, price := range []struct {
+		value *float64
+		found bool
+	}{{&result.Open, r.hasOpen}, {&result.High, r.hasHigh}, {&result.Low, r.hasLow}} {
+		if !price.found {
+			*price.value = r.close
+		}
+	}
+	return result
+}
+
+func (provider *csvFilePricesProvider) parseRecord(record []string, indexes columnIndexes, location *time.Location) (result row, err error) {
+	if result.time, err = provider.parseDate(field(record, indexes.date), location); err != nil {
+		return
+	}
+	if result.close, err = provider.parseNumber(field(record, indexes.close), "close"); err != nil {
+		return
+	}
+
+	var hasVolume bool
+	optional := []struct {
+		index int
+		name  string
+		value *float64
+		found *bool
+	}{
+		{indexes.open, "open", &result.open, &result.hasOpen},
+		{indexes.high, "high", &result.high, &result.hasHigh},
+		{indexes.low, "low", &result.low, &result.hasLow},
+		{indexes.volume, "volume", &result.volume, &hasVolume},
+	}
+	for _, column := range optional {
+		if str := field(record, column.index); column.index >= 0 && str != "" {
+			if *column.value, err = provider.parseNumber(str, column.name); err != nil {
+				return
+			}
+			*column.found = true
+		}
+	}
+	return result, nil
+}
+
+// location returns the configured location, the exchange one of the ticker market by default.
+func (provider *csvFilePricesProvider) location(ticker entity.Ticker) *time.Location {
+	if provider.config.Location != nil {
+		return provider.config.Location
+	}
+	return entity.CalendarOf(ticker.Market).Location
+}
+
+func (provider *csvFilePricesProvider) parseDate(str string, location *time.Location) (result time.Time, err error) {
+	for _, layout := range provider.config.DateLayouts {
+		if result, err = time.ParseInLocation(layout, str, location); err == nil {
+			return result, nil
+		}
+	}
+	return result, errors.Errorf("unable to parse date %q", str)
+}
+
+// Numbers grouping digits by three, by thousands separator.
+var thousandsGrouping = map[string]*regexp.Regexp{
+	",": regexp.MustCompile(`^[-+]?\d{1,3}(,\d{3})+(\.\d*)?$`),
+	".": regexp.MustCompile(`^[-+]?\d{1,3}(\.\d{3})+(,\d*)?$`),
+}
+
+func (provider *csvFilePricesProvider) parseNumber(str string, column string) (float64, error) {
+	number, thousands := str, ","
+	if provider.config.DecimalSeparator == ',' {
+		thousands = "."
+	}
+	if strings.Contains(number, thousands) {
+		if !thousandsGrouping[thousands].MatchString(number) {
+			return 0, errors.Errorf("ambiguous %s value %q", column, str)
+		}
+		number = strings.Replace(number, thousands, "", -1)
+	}
+	number = strings.Replace(number, ",", ".", 1)
+
+	result, err := strconv.ParseFloat(number, 64)
+	return result, errors.Wrapf(err, "invalid %s value %q", column, str)
+}
+
+func field(record []string, index int) string {
+	if index < 0 || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
@@ -0,0 +1,151 @@
+package csvfile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"org.alex859/stockprices/domain/entity"
+)
+
+var may4 = time.Date(2018, time.May, 4, 0, 0, 0, 0, time.UTC)
+var may5 = time.Date(2018, time.May, 5, 0, 0, 0, 0, time.UTC)
+var may6 = time.Date(2018, time.May, 6, 0, 0, 0, 0, time.UTC)
+var may7 = time.Date(2018, time.May, 7, 0, 0, 0, 0, time.UTC)
+var may8 = time.Date(2018, time.May, 8, 0, 0, 0, 0, time.UTC)
+var may9 = time.Date(2018, time.May, 9, 0, 0, 0, 0, time.UTC)
+var may10 = time.Date(2018, time.May, 10, 0, 0, 0, 0, time.UTC)
+
+// on returns the start of the given day of May 2018 on the exchange of the market.
+func on(day int, market string) time.Time {
+	return time.Date(2018, time.May, day, 0, 0, 0, 0, entity.CalendarOf(market).Location)
+}
+
+func Test_GetHistoricalPrices_WHEN_AllGood_THEN_ReturnOrderedPricesInInterval(t *testing.T) {
+	provider := NewCSVFilePricesProvider(Config{Dir: "testdata"})
+	ticker := entity.Ticker{Market: "LON", Symbol: "ANP"}
+	interval, _ := entity.NewDateInterval(may5, may9)
+
+	result, err := provider.GetHistoricalPrices(context.Background(), ticker, interval)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, entity.PriceHistory{
+			TickerInfo: entity.TickerInfo{Ticker: ticker},
+			Prices: entity.PriceList{
+				{Price: 12.30, Time: on(7, "LON")},
+				{Price: 12.50, Time: on(8, "LON")},
+				{Price: 12.60, Time: on(9, "LON")},
+			},
+			Bars: entity.BarList{
+				{Time: on(7, "LON"), Open: 12.25, High: 12.35, Low: 12.10, Close: 12.30, Volume: 8700},
+				{Time: on(8, "LON"), Open: 12.30, High: 12.60, Low: 12.20, Close: 12.50, Volume: 10500},
+				{Time: on(9, "LON"), Open: 12.50, High: 12.70, Low: 12.40, Close: 12.60, Volume: 11200},
+			},
+		}, result)
+	}
+}
+
+func Test_GetHistoricalPrices_WHEN_CustomLayoutAndColumns_THEN_ReturnPrices(t *testing.T) {
+	provider := NewCSVFilePricesProvider(Config{
+		Dir:         "testdata",
+		DateLayouts: []string{"02/01/2006"},
+		Columns:     Columns{Date: "day", Close: "close"},
+		Comma:       ';',
+	})
+	ticker := entity.Ticker{Market: "NYSE", Symbol: "SQ"}
+	interval, _ := entity.NewDateInterval(may4, may10)
+
+	result, err := provider.GetHistoricalPrices(context.Background(), ticker, interval)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, entity.PriceList{{Price: 80.25, Time: on(5, "NYSE")}, {Price: 81.5, Time: on(6, "NYSE")}}, result.Prices)
+		assert.Nil(t, result.Bars, "no bars without open, high and low columns")
+	}
+}
+
+func Test_GetHistoricalPrices_WHEN_DecimalComma_THEN_ReadNumbers(t *testing.T) {
+	ticker := entity.Ticker{Market: "EPA", Symbol: "AIR"}
+	interval, _ := entity.NewDateInterval(may4, may10)
+
+	provider := NewCSVFilePricesProvider(Config{Dir: "testdata", Comma: ';', DecimalSeparator: ','})
+	result, err := provider.GetHistoricalPrices(context.Background(), ticker, interval)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, entity.PriceList{{Price: 1080.25, Time: on(7, "EPA")}, {Price: 80.5, Time: on(8, "EPA")}}, result.Prices)
+	}
+
+	provider = NewCSVFilePricesProvider(Config{Dir: "testdata", Comma: ';'})
+	_, err = provider.GetHistoricalPrices(context.Background(), ticker, interval)
+
+	if assert.Error(t, err, "decimal commas are not thousands separators") {
+		assert.Equal(t, entity.ParseFailure, entity.KindOf(err))
+	}
+}
+
+func Test_GetHistoricalPrices_WHEN_EmptyOrZeroCells_THEN_FillOnlyEmptyOnes(t *testing.T) {
+	provider := NewCSVFilePricesProvider(Config{Dir: "testdata"})
+	interval, _ := entity.NewDateInterval(may4, may10)
+
+	result, err := provider.GetHistoricalPrices(context.Background(), entity.Ticker{Market: "LON", Symbol: "ZERO"}, interval)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, entity.BarList{{Time: on(7, "LON"), Open: 0.01, High: 0.02, Low: 0, Close: 0.01}}, result.Bars)
+	}
+}
+
+func Test_GetHistoricalPrices_WHEN_NoFile_THEN_ReturnNothingFound(t *testing.T) {
+	provider := NewCSVFilePricesProvider(Config{Dir: "testdata"})
+	interval, _ := entity.NewDateInterval(may4, may10)
+
+	_, err := provider.GetHistoricalPrices(context.Background(), entity.Ticker{Market: "LON", Symbol: "SDRY"}, interval)
+
+	assert.IsType(t, entity.ErrNothingFound{}, err)
+}
+
+func Test_GetHistoricalPrices_WHEN_NothingInInterval_THEN_ReturnNothingFound(t *testing.T) {
+	provider := NewCSVFilePricesProvider(Config{Dir: "testdata"})
+	june1 := time.Date(2018, time.June, 1, 0, 0, 0, 0, time.UTC)
+	interval, _ := entity.NewDateInterval(june1, june1.AddDate(0, 0, 10))
+
+	_, err := provider.GetHistoricalPrices(context.Background(), entity.Ticker{Market: "LON", Symbol: "ANP"}, interval)
+
+	assert.IsType(t, entity.ErrNothingFound{}, err)
+}
+
+func Test_GetHistoricalPrices_WHEN_WrongPrice_THEN_ReturnError(t *testing.T) {
+	provider := NewCSVFilePricesProvider(Config{Dir: "testdata"})
+	interval, _ := entity.NewDateInterval(may4, may10)
+
+	_, err := provider.GetHistoricalPrices(context.Background(), entity.Ticker{Market: "LON", Symbol: "BAD"}, interval)
+
+	if assert.Error(t, err) {
+		assert.Equal(t, entity.ParseFailure, entity.KindOf(err))
+		assert.Contains(t, err.Error(), "line 3")
+	}
+}
+
+func Test_GetHistoricalPrices_WHEN_MissingCloseColumn_THEN_ReturnError(t *testing.T) {
+	provider := NewCSVFilePricesProvider(Config{Dir: "testdata"})
+	interval, _ := entity.NewDateInterval(may4, may10)
+
+	_, err := provider.GetHistoricalPrices(context.Background(), entity.Ticker{Market: "LON", Symbol: "NOCLOSE"}, interval)
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "missing close column")
+	}
+}
+
+func Test_GetHistoricalPrices_WHEN_SymbolPointsOutside_THEN_DoNotReadIt(t *testing.T) {
+	provider := NewCSVFilePricesProvider(Config{Dir: "testdata/nowhere"})
+	interval, _ := entity.NewDateInterval(may4, may10)
+
+	_, err := provider.GetHistoricalPrices(context.Background(), entity.Ticker{Market: "x", Symbol: "../../LON_ANP"}, interval)
+	assert.IsType(t, entity.ErrNothingFound{}, err)
+
+	provider = NewCSVFilePricesProvider(Config{Dir: "testdata/nowhere", FileName: func(ticker entity.Ticker) string {
+		return ticker.Symbol + ".csv"
+	}})
+	_, err = provider.GetHistoricalPrices(context.Background(), entity.Ticker{Market: "LON", Symbol: "../LON_ANP"}, interval)
+	assert.IsType(t, entity.ErrValidation{}, err)
+}
@@ -0,0 +1,189 @@
+package googlefinance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Guard line Google puts in front of async responses to prevent JSON hijacking.
+const asyncResponseGuard = ")]}'"
+
+type (
+	// Chunk is a single chunk of a Google async response.
+	// The response is made of the guard line followed by chunks in the form "<hex length>;<payload>",
+	// where the length is the number of characters in the payload, trailing new line included.
+	Chunk struct {
+		Length  int
+		Payload string
+	}
+
+	// DataBlock is a named block of data carried by a chunk.
+	// Value holds the block content, which for most blocks is itself JSON encoded.
+	DataBlock struct {
+		Name  string
+		Value string
+	}
+)
+
+// DecodeAsyncResponse splits a Google async response into its chunks.
+func DecodeAsyncResponse(str string) ([]Chunk, error) {
+	if !strings.HasPrefix(str, asyncResponseGuard) {
+		return nil, errors.New("missing async response guard")
+	}
+
+	text := []rune(strings.TrimLeft(str[len(asyncResponseGuard):], "\r\n"))
+	var chunks []Chunk
+	for pos := 0; pos < len(text); {
+		separator := indexRune(text[pos:], ';')
+		if separator < 0 {
+			return chunks, errors.Errorf("chunk %d: missing length separator", len(chunks))
+		}
+
+		lengthStr := string(text[pos : pos+separator])
+		length, err := strconv.ParseInt(lengthStr, 16, 32)
+		if err != nil || length < 0 {
+			return chunks, errors.Errorf("chunk %d: invalid length %q", len(chunks), abbreviate(lengthStr))
+		}
+
+		start := pos + separator + 1
+		end := start + int(length)
+		if end > len(text) {
+			// the final new line is sometimes missing
+			if end-len(text) > 1 {
+				return chunks, errors.Errorf("chunk %d: declares %d characters but only %d are left", len(chunks), length, len(text)-start)
+			}
+			end = len(text)
+		}
+
+		chunks = append(chunks, Chunk{Length: int(length), Payload: string(text[start:end])})
+		pos = end
+	}
+
+	return chunks, nil
+}
+
+// DataBlocks returns the named data blocks carried by the chunk.
+// Chunks carrying data are JSON arrays of components, each one containing entries listing blocks:
+// [null,[[componentId,[[id,id,id,[[name,null,null,null,null,[null,value]],...]],...]],...]]
+// Chunks not in this shape, like the ones carrying styles or markup, have no blocks.
+func (chunk Chunk) DataBlocks() []DataBlock {
+	var root interface{}
+	if err := json.Unmarshal([]byte(chunk.Payload), &root); err != nil {
+		return nil
+	}
+
+	var result []DataBlock
+	components, _ := at(root, "chunk").index(1).list()
+	for _, component := range components {
+		entries, _ := at(component, "component").index(1).list()
+		for _, entry := range entries {
+			blocks, _ := at(entry, "entry").index(3).list()
+			for _, block := range blocks {
+				name, err := at(block, "block").index(0).str()
+				if err != nil {
+					continue
+				}
+				value, err := at(block, "block").index(5).index(1).str()
+				if err != nil {
+					continue
+				}
+				result = append(result, DataBlock{Name: name, Value: value})
+			}
+		}
+	}
+	return result
+}
+
+// findDataBlock returns the value of the first block with the given name in the chunks.
+func findDataBlock(chunks []Chunk, name string) (string, bool) {
+	for _, chunk := range chunks {
+		for _, block := range chunk.DataBlocks() {
+			if block.Name == name {
+				return block.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// jsonNode walks decoded JSON, remembering the path followed and the first error met.
+type jsonNode struct {
+	value interface{}
+	path  string
+	err   error
+}
+
+func at(value interface{}, name string) jsonNode {
+	return jsonNode{value: value, path: name}
+}
+
+func (node jsonNode) index(i int) jsonNode {
+	if node.err != nil {
+		return node
+	}
+
+	path := fmt.Sprintf("%s[%d]", node.path, i)
+	list, ok := node.value.([]interface{})
+	if !ok {
+		return jsonNode{path: path, err: errors.Errorf("%s is not a list", node.path)}
+	}
+	if i < 0 || i >= len(list) {
+		return jsonNode{path: path, err: errors.Errorf("%s has %d elements, %d expected at least", node.path, len(list), i+1)}
+	}
+	return jsonNode{value: list[i], path: path}
+}
+
+func (node jsonNode) list() ([]interface{}, error) {
+	if node.err != nil {
+		return nil, node.err
+	}
+	list, ok := node.value.([]interface{})
+	if !ok {
+		return nil, errors.Errorf("%s is not a list", node.path)
+	}
+	return list, nil
+}
+
+func (node jsonNode) str() (string, error) {
+	if node.err != nil {
+		return "", node.err
+	}
+	str, ok := node.value.(string)
+	if !ok {
+		return "", errors.Errorf("%s is not a string", node.path)
+	}
+	return str, nil
+}
+
+func (node jsonNode) number() (float64, error) {
+	if node.err != nil {
+		return 0, node.err
+	}
+	number, ok := node.value.(float64)
+	if !ok {
+		return 0, errors.Errorf("%s is not a number", node.path)
+	}
+	return number, nil
+}
+
+func indexRune(runes []rune, r rune) int {
+	for i, current := range runes {
+		if current == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// abbreviate keeps error messages short when the input is not what we expect.
+func abbreviate(str string) string {
+	const maxLength = 20
+	if runes := []rune(str); len(runes) > maxLength {
+		return string(runes[:maxLength]) + "..."
+	}
+	return str
+}
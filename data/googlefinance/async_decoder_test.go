@@ -0,0 +1,74 @@
+package googlefinance
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DecodeAsyncResponse_AllGood(t *testing.T) {
+	str, _ := ioutil.ReadFile("testdata/google_finance_day_all_good")
+	chunks, err := DecodeAsyncResponse(string(str))
+
+	if assert.NoError(t, err) && assert.Equal(t, 5, len(chunks)) {
+		assert.Equal(t, Chunk{Length: 27, Payload: "[\"WTSRW-P3OIvLgAbsn6yIDQ\"]\n"}, chunks[0])
+		assert.Equal(t, Chunk{Length: 4, Payload: "[2]\n"}, chunks[1])
+		assert.Equal(t, 0x2297, chunks[2].Length)
+		assert.Equal(t, 0x74e, chunks[4].Length)
+	}
+}
+
+func Test_DecodeAsyncResponse_Unknown(t *testing.T) {
+	str, _ := ioutil.ReadFile("testdata/google_finance_unknown")
+	chunks, err := DecodeAsyncResponse(string(str))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Chunk{
+			{Length: 25, Payload: "[\"ijSRW9ugI4iAaY-DuLgB\"]\n"},
+			{Length: 4, Payload: "[1]\n"},
+			{Length: 4, Payload: "[5]"},
+		}, chunks)
+	}
+}
+
+func Test_DecodeAsyncResponse_LengthInCharacters(t *testing.T) {
+	chunks, err := DecodeAsyncResponse(")]}'\n6;[\"£\"]\n4;[2]\n")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Chunk{{Length: 6, Payload: "[\"£\"]\n"}, {Length: 4, Payload: "[2]\n"}}, chunks)
+	}
+}
+
+func Test_DecodeAsyncResponse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		str  string
+	}{
+		{"Missing guard", "4;[2]\n"},
+		{"Missing separator", ")]}'\n4[2]\n"},
+		{"Invalid length", ")]}'\nzz;[2]\n"},
+		{"Truncated chunk", ")]}'\n10;[2]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeAsyncResponse(tt.str)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_DataBlocks(t *testing.T) {
+	str, _ := ioutil.ReadFile("testdata/google_finance_day_all_good")
+	chunks, _ := DecodeAsyncResponse(string(str))
+
+	var names []string
+	for _, chunk := range chunks {
+		for _, block := range chunk.DataBlocks() {
+			names = append(names, block.Name)
+		}
+	}
+
+	assert.Equal(t, []string{"data", "common_entity_data"}, names)
+	assert.Empty(t, chunks[2].DataBlocks())
+}
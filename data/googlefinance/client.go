@@ -1,11 +1,16 @@
 package googlefinance
 
-import "org.alex859/stockprices/domain/entity"
+import (
+	"context"
+	"time"
+	"org.alex859/stockprices/domain/entity"
+)
 
 type (
 	// PricesFetcher goes off to GoogleFinance to retrieve prices data.
+	// In flight requests are cancelled when the context is done.
 	PricesFetcher interface {
-		FetchPrices(market string, symbol string, period Period) (Response, error)
+		FetchPrices(ctx context.Context, market string, symbol string, period Period) (Response, error)
 	}
 
 	// ResponseToPriceHistoryConverter converts the response from GoogleFinance into a PriceHistory.
@@ -33,6 +38,8 @@ type (
 		LastPrice     string
 		LastPriceTime string
 		PricesRows    []PriceRow
+		// FetchedAt is when the response was received, LastPriceTime is relative to it as it comes without a year.
+		FetchedAt time.Time
 	}
 
 	// PriceRow is a Single time/price.
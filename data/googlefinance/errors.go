@@ -0,0 +1,79 @@
+package googlefinance
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ResponseErrorKind tells why a response from Google could not be read.
+type ResponseErrorKind int
+
+const (
+	// FormatChanged means the response is not in the shape we know, Google most likely changed it.
+	FormatChanged ResponseErrorKind = iota
+	// ConsentPage means Google served a consent wall or a captcha page instead of data.
+	ConsentPage
+	// EmptyResult means the response is well formed but has no data, e.g. for an unknown ticker.
+	EmptyResult
+)
+
+func (kind ResponseErrorKind) String() string {
+	switch kind {
+	case FormatChanged:
+		return "format changed"
+	case ConsentPage:
+		return "consent or captcha page"
+	case EmptyResult:
+		return "empty result"
+	default:
+		return "unknown"
+	}
+}
+
+// ResponseError is returned when a response from Google cannot be read.
+type ResponseError struct {
+	Kind   ResponseErrorKind
+	Reason string
+}
+
+func (err ResponseError) Error() string {
+	return fmt.Sprintf("unable to read google finance response (%s): %s", err.Kind, err.Reason)
+}
+
+func newResponseError(kind ResponseErrorKind, err error) ResponseError {
+	return ResponseError{Kind: kind, Reason: err.Error()}
+}
+
+// Text found in the pages Google serves when it wants a consent before serving results.
+var consentPageMarkers = []string{
+	"consent.google.com",
+	"before you continue to google",
+}
+
+// Text found in the pages Google serves when it suspects automated traffic.
+var captchaPageMarkers = []string{
+	"our systems have detected unusual traffic",
+	"/sorry/index",
+	"g-recaptcha",
+	"captcha-form",
+}
+
+// looksLikeConsentPage checks if the page is a consent wall or a captcha page.
+func looksLikeConsentPage(page string) bool {
+	return containsAny(page, consentPageMarkers) || looksLikeCaptchaPage(page)
+}
+
+// looksLikeCaptchaPage checks if the page is a captcha page.
+func looksLikeCaptchaPage(page string) bool {
+	return containsAny(page, captchaPageMarkers)
+}
+
+func containsAny(page string, markers []string) bool {
+	lower := strings.ToLower(page)
+	for _, marker := range markers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@
 
 package googlefinance
 
+import context "context"
 import mock "github.com/stretchr/testify/mock"
 
 // MockPricesFetcher is an autogenerated mock type for the PricesFetcher type
@@ -9,20 +10,20 @@ type MockPricesFetcher struct {
 	mock.Mock
 }
 
-// FetchPrices provides a mock function with given fields: market, symbol, period
-func (_m *MockPricesFetcher) FetchPrices(market string, symbol string, period Period) (Response, error) {
-	ret := _m.Called(market, symbol, period)
+// FetchPrices provides a mock function with given fields: ctx, market, symbol, period
+func (_m *MockPricesFetcher) FetchPrices(ctx context.Context, market string, symbol string, period Period) (Response, error) {
+	ret := _m.Called(ctx, market, symbol, period)
 
 	var r0 Response
-	if rf, ok := ret.Get(0).(func(string, string, Period) Response); ok {
-		r0 = rf(market, symbol, period)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, Period) Response); ok {
+		r0 = rf(ctx, market, symbol, period)
 	} else {
 		r0 = ret.Get(0).(Response)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string, Period) error); ok {
-		r1 = rf(market, symbol, period)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, Period) error); ok {
+		r1 = rf(ctx, market, symbol, period)
 	} else {
 		r1 = ret.Error(1)
 	}
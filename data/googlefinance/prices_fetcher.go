@@ -1,34 +1,65 @@
 package googlefinance
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"github.com/Knetic/govaluate"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/pkg/errors"
 	"golang.org/x/net/html"
 	"io/ioutil"
 	"log"
 	"net/http"
-	"reflect"
-	"regexp"
+	"strconv"
 	"strings"
+	"time"
+
+	"org.alex859/stockprices/domain/entity"
 )
 
 // Service to go off to GoogleFinance and gather prices.
 // Does not handle format errors, just returns a struct with string values to be validated later.
-// TODO Google finance response parsing can be done in a decent way.
+// Transient failures (throttling, server errors, transport errors) are retried according to its RetryPolicy.
 type defaultPricesFetcher struct {
-	httpClient *http.Client
+	httpClient  *http.Client
+	retryPolicy RetryPolicy
+	sleep       func(context.Context, time.Duration) error
+	random      func() float64
+	now         func() time.Time
+	symbols     *symbolCache
 }
 
 // NewDefaultPricesFetcher creates a new defaultGoogleFinancePricesFetcher.
-func NewDefaultPricesFetcher(client *http.Client) *defaultPricesFetcher {
-	return &defaultPricesFetcher{client}
+func NewDefaultPricesFetcher(client *http.Client, options ...FetcherOption) *defaultPricesFetcher {
+	fetcher := &defaultPricesFetcher{
+		httpClient:  client,
+		retryPolicy: DefaultRetryPolicy,
+		sleep:       sleepContext,
+		random:      defaultRandom,
+		now:         time.Now,
+	}
+	for _, option := range options {
+		option(fetcher)
+	}
+
+	return fetcher
 }
 
-func (gp *defaultPricesFetcher) FetchPrices(market string, symbol string, period Period) (result Response, err error) {
+func (gp *defaultPricesFetcher) FetchPrices(ctx context.Context, market string, symbol string, period Period) (result Response, err error) {
 	ticker := fmt.Sprintf("%s:%s", market, symbol)
-	symbolEncoded, eiCode, err := gp.searchSymbolAndEi(ticker)
+	result, cached, err := gp.fetchPrices(ctx, ticker, period)
+	if err != nil && cached && gp.worthSearchingAgain(ctx, err) {
+		log.Printf("Cached symbol for ticker: %s did not work, searching it again. Error: %s", ticker, err)
+		gp.symbols.invalidate(ticker)
+		result, _, err = gp.fetchPrices(ctx, ticker, period)
+	}
+
+	return result, err
+}
+
+// fetchPrices does the work for FetchPrices, telling whether the symbol came from the cache.
+func (gp *defaultPricesFetcher) fetchPrices(ctx context.Context, ticker string, period Period) (result Response, cached bool, err error) {
+	symbolEncoded, eiCode, cached, err := gp.resolveSymbol(ctx, ticker)
 	if err != nil {
 		message := fmt.Sprintf("unable to get symbol for ticker: %s. Error: %s", ticker, err)
 		log.Print(message)
@@ -36,7 +67,7 @@ func (gp *defaultPricesFetcher) FetchPrices(market string, symbol string, period
 		return
 	}
 
-	quotes, err := gp.getQuotesText(symbolEncoded, eiCode, period)
+	quotes, err := gp.getQuotesText(ctx, symbolEncoded, eiCode, period)
 
 	if err != nil {
 		message := fmt.Sprintf("unable to get quotes for ticker: %s. Error: %s", ticker, err)
@@ -45,12 +76,38 @@ func (gp *defaultPricesFetcher) FetchPrices(market string, symbol string, period
 		return
 	}
 
-	return readGoogleResponse(quotes)
+	result, err = readGoogleResponse(quotes)
+	result.FetchedAt = gp.now()
+	return
 }
 
-func (gp *defaultPricesFetcher) getQuotesText(symbolEncoded, eiCode string, period Period) (string, error) {
+// resolveSymbol gets the data-mid and ei from the symbol cache when possible, searching for them otherwise.
+func (gp *defaultPricesFetcher) resolveSymbol(ctx context.Context, ticker string) (dataMid string, ei string, cached bool, err error) {
+	if gp.symbols != nil {
+		if dataMid, ei, found := gp.symbols.get(ticker); found {
+			return dataMid, ei, true, nil
+		}
+	}
+
+	if dataMid, ei, err = gp.searchSymbolAndEi(ctx, ticker); err == nil && gp.symbols != nil {
+		gp.symbols.put(ticker, dataMid, ei)
+	}
+	return dataMid, ei, false, err
+}
+
+// worthSearchingAgain tells if a failure with cached symbol values may be fixed by searching the symbol again.
+// It would not help when we ran out of time or when Google is refusing us.
+func (gp *defaultPricesFetcher) worthSearchingAgain(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	_, throttled := errors.Cause(err).(entity.ErrUpstreamThrottled)
+	return !throttled
+}
+
+func (gp *defaultPricesFetcher) getQuotesText(ctx context.Context, symbolEncoded, eiCode string, period Period) (string, error) {
 	const quotesURLTemplate = "https://www.google.com/async/finance_wholepage_chart?ei=%s&yv=3&async=mid_list:%s,period:%s,interval:%s,extended:true,element_id:fw-uid_%s_1,_id:fw-uid_%s_1,_pms:s,_fmt:pc"
-	quotesText, err := gp.htmlFrom(fmt.Sprintf(quotesURLTemplate, eiCode, symbolEncoded, period.Value().Str, period.Value().Interval, eiCode, eiCode))
+	quotesText, err := gp.htmlFrom(ctx, fmt.Sprintf(quotesURLTemplate, eiCode, symbolEncoded, period.Value().Str, period.Value().Interval, eiCode, eiCode))
 	if err != nil {
 		return "", errors.Wrap(err, "unable to read HTML")
 	}
@@ -58,9 +115,17 @@ func (gp *defaultPricesFetcher) getQuotesText(symbolEncoded, eiCode string, peri
 	return quotesText, nil
 }
 
-func (gp *defaultPricesFetcher) searchSymbolAndEi(ticker string) (dataMid string, ei string, err error) {
+func (gp *defaultPricesFetcher) searchSymbolAndEi(ctx context.Context, ticker string) (dataMid string, ei string, err error) {
 	const searchTemplate = "https://www.google.com/search?hl=en&q=%s&btnG=Google+Search&tbs=0&safe=off&tbm=fin"
-	resultsDocument, err := gp.document(gp.htmlFrom(fmt.Sprintf(searchTemplate, ticker)))
+	resultsPage, err := gp.htmlFrom(ctx, fmt.Sprintf(searchTemplate, ticker))
+	if err != nil {
+		return "", "", errors.Wrap(err, "unable to find symbol")
+	}
+	if looksLikeConsentPage(resultsPage) {
+		return "", "", ResponseError{Kind: ConsentPage, Reason: fmt.Sprintf("searching symbol: %s", ticker)}
+	}
+
+	resultsDocument, err := gp.document(resultsPage, nil)
 	if err != nil {
 		return "", "", errors.Wrap(err, "unable to find symbol")
 	}
@@ -68,13 +133,13 @@ func (gp *defaultPricesFetcher) searchSymbolAndEi(ticker string) (dataMid string
 	dataMid, found := gp.findFirstAttributeValue(resultsDocument.Find("#rso > div > div").Nodes, "data-mid")
 	if !found {
 		message := fmt.Sprintf("unable to find symbol: %s", ticker)
-		return "", "", errors.New(message)
+		return "", "", ResponseError{Kind: EmptyResult, Reason: message}
 	}
 
 	ei, found = gp.findFirstAttributeValue(resultsDocument.Find(`#tophf > input[name="ei"]`).Nodes, "value")
 	if !found {
 		message := fmt.Sprintf("unable to find ei for symbol: %s", ticker)
-		return "", "", errors.New(message)
+		return "", "", ResponseError{Kind: FormatChanged, Reason: message}
 	}
 
 	return dataMid, ei, nil
@@ -93,6 +158,10 @@ func (gp *defaultPricesFetcher) findFirstAttributeValue(nodes []*html.Node, attr
 }
 
 func (gp *defaultPricesFetcher) document(html string, err error) (*goquery.Document, error) {
+	if err != nil {
+		return nil, err
+	}
+
 	document, err := goquery.NewDocumentFromReader(strings.NewReader(html))
 	if err != nil {
 		return nil, errors.Wrap(err, "erro querying html")
@@ -101,12 +170,70 @@ func (gp *defaultPricesFetcher) document(html string, err error) (*goquery.Docum
 	return document, nil
 }
 
-func (gp *defaultPricesFetcher) htmlFrom(url string) (string, error) {
+// htmlFrom gets the page at the given url, retrying transient failures.
+// Throttled and blocked responses end up as an entity.ErrUpstreamThrottled.
+func (gp *defaultPricesFetcher) htmlFrom(ctx context.Context, url string) (string, error) {
+	for attempt := 1; ; attempt++ {
+		page, err := gp.fetchPage(ctx, url)
+		if err == nil {
+			return page, nil
+		}
+		if ctx.Err() != nil {
+			return "", errors.Wrap(ctx.Err(), "unable to talk to remote server")
+		}
+
+		delay, retry := gp.retryDelay(attempt, err)
+		if !retry {
+			return "", gp.finalError(err)
+		}
+
+		log.Printf("Attempt %d of %d to get %s failed, retrying in %s. Error: %s", attempt, gp.retryPolicy.MaxAttempts, url, delay, err)
+		if err = gp.sleep(ctx, delay); err != nil {
+			return "", errors.Wrap(err, "unable to talk to remote server")
+		}
+	}
+}
+
+// retryDelay tells if a failed attempt should be retried and after how long.
+func (gp *defaultPricesFetcher) retryDelay(attempt int, err error) (time.Duration, bool) {
+	if attempt >= gp.retryPolicy.MaxAttempts {
+		return 0, false
+	}
+
+	statusErr, isStatusErr := errors.Cause(err).(statusError)
+	if isStatusErr && !statusErr.class.retryable() {
+		return 0, false
+	}
+
+	delay := gp.retryPolicy.backoff(attempt, gp.random)
+	if isStatusErr && statusErr.retryAfter > delay {
+		// No point in waiting longer than we are willing to, the caller gets the throttled error with the wait instead.
+		if statusErr.retryAfter > gp.retryPolicy.MaxDelay {
+			return 0, false
+		}
+		delay = statusErr.retryAfter
+	}
+
+	return delay, true
+}
+
+func (gp *defaultPricesFetcher) finalError(err error) error {
+	statusErr, ok := errors.Cause(err).(statusError)
+	if ok && (statusErr.class == throttled || statusErr.class == blocked) {
+		return entity.NewErrUpstreamThrottled(sourceName, statusErr.Error(), statusErr.retryAfter)
+	}
+
+	return err
+}
+
+// fetchPage makes a single request, returning a statusError for responses that are not successful.
+func (gp *defaultPricesFetcher) fetchPage(ctx context.Context, url string) (string, error) {
 	const userAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_10_1) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/39.0.2171.95 Safari/537.36"
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return "", errors.Wrap(err, "unable to create request")
 	}
+	req = req.WithContext(ctx)
 	req.Header = http.Header{
 		"User-Agent": []string{userAgent},
 	}
@@ -126,104 +253,99 @@ func (gp *defaultPricesFetcher) htmlFrom(url string) (string, error) {
 		return "", errors.Wrap(err, "unable to read response body")
 	}
 
-	return string(htmlBytes), nil
+	page := string(htmlBytes)
+	if class := classifyResponse(response.StatusCode, page); class != success {
+		return "", statusError{
+			class:      class,
+			statusCode: response.StatusCode,
+			retryAfter: parseRetryAfter(response.Header.Get("Retry-After"), gp.now()),
+		}
+	}
+
+	return page, nil
 }
 
+// readGoogleResponse maps the chart data block of a Google async response into a Response.
+// Whatever the input, failures are returned as a ResponseError, every access to the input being checked.
+// The chart data block is a JSON list where:
+// - [0][2][0][0] lists the chart rows as [x, y, price, change, minutes since epoch], x and y being chart coordinates.
+//   Rows carry a single price and no OHLC, so Google histories come without bars.
+// - [2][0] describes the quote, with market at [3], currency at [7] and [17] as
+//   [[id], name, symbol, last price, last price as number, change, change %, ?, last price time, ...]
 func readGoogleResponse(str string) (Response, error) {
-	var financeResponse Response
-	regex := regexp.MustCompile(`[0-9a-z]+;\[null`)
-	text := ""
-	found := false
-	for _, row := range strings.Split(str, "\n") {
-		if regex.MatchString(row) {
-			found = true
-		}
-		if found {
-			text = text + row
-		}
+	if !strings.HasPrefix(str, asyncResponseGuard) && looksLikeConsentPage(str) {
+		return Response{}, ResponseError{Kind: ConsentPage, Reason: "chart data requested"}
 	}
 
-	if !found {
-		message := fmt.Sprintf("unexpected google response format reading: %s", str)
-		return financeResponse, errors.New(message)
+	chunks, err := DecodeAsyncResponse(str)
+	if err != nil {
+		return Response{}, newResponseError(FormatChanged, errors.Wrap(err, "unable to decode response"))
 	}
 
-	start := strings.Index(text, `"[`)
-	end := strings.Index(text, `]\n"`)
+	block, found := findDataBlock(chunks, "data")
+	if !found {
+		return Response{}, ResponseError{Kind: EmptyResult, Reason: "no chart data in response"}
+	}
 
-	stringToAnalyse := text[start+1 : end+1]
-	stringToAnalyse = strings.Replace(stringToAnalyse, `\n`, "", -1)
-	stringToAnalyse = strings.Replace(stringToAnalyse, `\"`, `"`, -1)
-	stringToAnalyse = strings.Replace(stringToAnalyse, "[", "(", -1)
-	stringToAnalyse = strings.Replace(stringToAnalyse, "]", ")", -1)
-	stringToAnalyse = strings.Replace(stringToAnalyse, "null", `0`, -1)
+	var data interface{}
+	if err = json.Unmarshal([]byte(block), &data); err != nil {
+		return Response{}, newResponseError(FormatChanged, errors.Wrap(err, "unable to decode chart data"))
+	}
 
-	evaluableExpression, err := govaluate.NewEvaluableExpression(stringToAnalyse)
+	result, err := readChartData(at(data, "data"))
 	if err != nil {
-		return financeResponse, errors.Wrap(err, "unable to parse google finance response")
+		return Response{}, newResponseError(FormatChanged, err)
 	}
-	r, err := evaluableExpression.Evaluate(nil)
-	if err != nil {
-		return financeResponse, errors.Wrap(err, "unable to parse google finance response")
+	if len(result.PricesRows) == 0 {
+		return Response{}, ResponseError{Kind: EmptyResult, Reason: "no chart rows in response"}
 	}
 
-	data, err := i2s(r)
-	if err != nil {
-		return financeResponse, errors.Wrap(err, "unable to parse google finance response")
-	}
-	data2, err := i2s(data[2])
+	return result, nil
+}
+
+func readChartData(data jsonNode) (result Response, err error) {
+	rows, err := data.index(0).index(2).index(0).index(0).list()
 	if err != nil {
-		return financeResponse, errors.Wrap(err, "unable to parse google finance response")
+		return Response{}, errors.Wrap(err, "unexpected chart rows")
 	}
-	prices := []PriceRow{{Price: toString(data2[2]), Time: toString(data2[4])}}
-	for i := 5; i < len(data2)-5; i++ {
-		d, err := i2s(data2[i])
-		if err != nil {
-			return financeResponse, errors.Wrap(err, "unable to parse google finance response")
+	for i := range rows {
+		var row PriceRow
+		if row, err = readPriceRow(data.index(0).index(2).index(0).index(0).index(i)); err != nil {
+			return Response{}, errors.Wrap(err, "unexpected chart row")
 		}
-
-		prices = append(prices, PriceRow{Price: toString(d[2]), Time: toString(d[4])})
+		result.PricesRows = append(result.PricesRows, row)
 	}
 
-	data6, err := i2s(data[6])
-	if err != nil {
-		data6, err = i2s(data[7])
-		if err != nil {
-			return financeResponse, errors.Wrap(err, "unable to parse google finance response")
-		}
+	quoteInfo := data.index(2).index(0)
+	quote := quoteInfo.index(17)
+	fields := []struct {
+		node  jsonNode
+		value *string
+	}{
+		{quoteInfo.index(3), &result.Market},
+		{quoteInfo.index(7), &result.Currency},
+		{quote.index(1), &result.Name},
+		{quote.index(2), &result.Symbol},
+		{quote.index(3), &result.LastPrice},
+		{quote.index(8), &result.LastPriceTime},
 	}
-	data617, err := i2s(data6[17])
-	if err != nil {
-		return financeResponse, errors.Wrap(err, "unable to parse google finance response")
+	for _, field := range fields {
+		if *field.value, err = field.node.str(); err != nil {
+			return Response{}, errors.Wrap(err, "unexpected quote info")
+		}
 	}
 
-	return Response{
-		Currency:      data6[7].(string),
-		Name:          data617[1].(string),
-		Market:        data6[3].(string),
-		Symbol:        data617[2].(string),
-		LastPrice:     toString(data617[4]),
-		LastPriceTime: data617[8].(string),
-		PricesRows:    prices,
-	}, nil
+	return result, nil
 }
 
-func i2s(slice interface{}) ([]interface{}, error) {
-	s := reflect.ValueOf(slice)
-	if s.Kind() != reflect.Slice {
-		message := fmt.Sprintf("unexpected non slice type: %s", slice)
-		return nil, errors.New(message)
+func readPriceRow(row jsonNode) (PriceRow, error) {
+	price, err := row.index(2).str()
+	if err != nil {
+		return PriceRow{}, err
 	}
-
-	ret := make([]interface{}, s.Len())
-
-	for i := 0; i < s.Len(); i++ {
-		ret[i] = s.Index(i).Interface()
+	minutes, err := row.index(4).number()
+	if err != nil {
+		return PriceRow{}, err
 	}
-
-	return ret, nil
-}
-
-func toString(anything interface{}) string {
-	return fmt.Sprintf("%v", anything)
+	return PriceRow{Price: price, Time: strconv.FormatFloat(minutes, 'f', -1, 64)}, nil
 }
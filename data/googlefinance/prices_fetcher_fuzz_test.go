@@ -0,0 +1,38 @@
+package googlefinance
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// FuzzReadGoogleResponse checks that any input ends up in a result or a ResponseError, never in a panic.
+// The corpus is seeded from the responses in testdata, run with: go test -fuzz=FuzzReadGoogleResponse
+func FuzzReadGoogleResponse(f *testing.F) {
+	files, err := filepath.Glob("testdata/google_finance_*")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(content))
+	}
+	f.Add(")]}'\n")
+	f.Add(")]}'\n4;[2]\n")
+
+	f.Fuzz(func(t *testing.T, str string) {
+		result, err := readGoogleResponse(str)
+		if err != nil {
+			if _, ok := err.(ResponseError); !ok {
+				t.Errorf("expected a ResponseError, got %T: %v", err, err)
+			}
+			return
+		}
+		if len(result.PricesRows) == 0 {
+			t.Errorf("expected price rows when no error is returned")
+		}
+	})
+}
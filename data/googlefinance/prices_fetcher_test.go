@@ -1,8 +1,13 @@
 package googlefinance
 
 import (
+	"context"
+	"encoding/json"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,6 +35,11 @@ func Test_readGoogleResponse_Day_AllGood(t *testing.T) {
 	assert.Equal(t, "ANP", result.Symbol)
 	assert.Equal(t, "GBX", result.Currency)
 	assert.Equal(t, 6, len(result.PricesRows))
+	assert.Equal(t, "Anpario", result.Name)
+	assert.Equal(t, "484.00", result.LastPrice)
+	assert.Equal(t, "6 Sep, 15:04 BST", result.LastPriceTime)
+	assert.Equal(t, PriceRow{Price: "483.00", Time: "25603690"}, result.PricesRows[0])
+	assert.Equal(t, PriceRow{Price: "484.00", Time: "25604105"}, result.PricesRows[5])
 }
 
 func Test_readGoogleResponse_WrongOrder(t *testing.T) {
@@ -46,3 +56,69 @@ func Test_readGoogleResponse_Unknown(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_readGoogleResponse_NoChartData(t *testing.T) {
+	_, err := readGoogleResponse(")]}'\n4;[2]\n")
+
+	assert.Error(t, err)
+}
+
+func Test_readGoogleResponse_MissingQuoteInfo(t *testing.T) {
+	str, _ := ioutil.ReadFile("testdata/google_finance_day_all_good")
+	chunks, _ := DecodeAsyncResponse(string(str))
+	block, _ := findDataBlock(chunks, "data")
+	var data interface{}
+	json.Unmarshal([]byte(block), &data)
+	data.([]interface{})[2] = []interface{}{}
+
+	_, err := readChartData(at(data, "data"))
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "data[2] has 0 elements")
+	}
+}
+
+func Test_readGoogleResponse_ErrorKinds(t *testing.T) {
+	unknown, _ := ioutil.ReadFile("testdata/google_finance_unknown")
+	wrongOrder, _ := ioutil.ReadFile("testdata/google_finance_day_unexpected_order")
+	allGood, _ := ioutil.ReadFile("testdata/google_finance_day_all_good")
+	tests := []struct {
+		name string
+		str  string
+		want ResponseErrorKind
+	}{
+		{"Unknown ticker is an empty result", string(unknown), EmptyResult},
+		{"Unexpected order is a format change", string(wrongOrder), FormatChanged},
+		{"Truncated body is a format change", string(allGood[:len(allGood)/2]), FormatChanged},
+		{"Empty body is a format change", "", FormatChanged},
+		{"Captcha page", "<html><body>Our systems have detected unusual traffic from your computer network.</body></html>", ConsentPage},
+		{"Consent wall", `<html><form action="https://consent.google.com/s">Before you continue to Google</form></html>`, ConsentPage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readGoogleResponse(tt.str)
+			if responseErr, ok := err.(ResponseError); assert.True(t, ok, "unexpected error %v", err) {
+				assert.Equal(t, tt.want, responseErr.Kind)
+			}
+		})
+	}
+}
+
+func Test_htmlFrom_WHEN_ContextDone_THEN_CancelRequest(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := NewDefaultPricesFetcher(server.Client()).htmlFrom(ctx, server.URL)
+
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < time.Second)
+}
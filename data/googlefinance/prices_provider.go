@@ -1,10 +1,14 @@
 package googlefinance
 
 import (
+	"context"
 	"org.alex859/stockprices/domain/entity"
 	"github.com/pkg/errors"
 )
 
+// Name given to Google Finance in errors.
+const sourceName = "google finance"
+
 // Retrieves prices from Google Finance.
 type googleFinanceHistoricalPricesProvider struct {
 	googlePricesClient PricesFetcher
@@ -16,25 +20,50 @@ func NewGoogleFinancePricesProvider(client PricesFetcher, converter ResponseConv
 	return &googleFinanceHistoricalPricesProvider{googlePricesClient: client, converter: converter}
 }
 
-func (provider *googleFinanceHistoricalPricesProvider) GetHistoricalPrices(ticker entity.Ticker, interval entity.DateInterval) (result entity.PriceHistory, err error) {
+func (provider *googleFinanceHistoricalPricesProvider) GetHistoricalPrices(ctx context.Context, ticker entity.Ticker, interval entity.DateInterval) (result entity.PriceHistory, err error) {
 	var googleResponse Response
-	if googleResponse, err = provider.googlePricesClient.FetchPrices(ticker.Market, ticker.Symbol, FromDateInterval(interval)); err == nil {
-		if result, err = provider.converter.ConvertToPriceHistory(googleResponse); err == nil {
-			result.Prices = result.Prices.FilterByInterval(interval)
-			return result, nil
-		}
+	if googleResponse, err = provider.googlePricesClient.FetchPrices(ctx, ticker.Market, ticker.Symbol, FromDateInterval(interval)); err != nil {
+		return result, providerError(ticker, err, "unable to get historical prices")
+	}
+	if result, err = provider.converter.ConvertToPriceHistory(googleResponse); err != nil {
+		return result, errors.Wrap(entity.NewErrMalformedData(sourceName, err.Error()), "unable to get historical prices")
 	}
 
-	return result, errors.Wrap(err, "unable to get historical prices")
+	result = result.FilterByInterval(interval)
+	return result, nil
 }
 
-func (provider *googleFinanceHistoricalPricesProvider) GetCurrentPrice(ticker entity.Ticker) (result entity.CurrentPrice, err error) {
+func (provider *googleFinanceHistoricalPricesProvider) GetCurrentPrice(ctx context.Context, ticker entity.Ticker) (result entity.CurrentPrice, err error) {
 	var googleResponse Response
-	if googleResponse, err = provider.googlePricesClient.FetchPrices(ticker.Market, ticker.Symbol, OneDay); err == nil {
-		if result, err = provider.converter.ConvertToCurrentPrice(googleResponse); err == nil {
-			return result, nil
+	if googleResponse, err = provider.googlePricesClient.FetchPrices(ctx, ticker.Market, ticker.Symbol, OneDay); err != nil {
+		return result, providerError(ticker, err, "unable to get current price")
+	}
+	if result, err = provider.converter.ConvertToCurrentPrice(googleResponse); err != nil {
+		return result, errors.Wrap(entity.NewErrMalformedData(sourceName, err.Error()), "unable to get current price")
+	}
+
+	return result, nil
+}
+
+// providerError translates the errors of the fetcher into the entity ones, so that callers can tell failures apart.
+// Errors that already are entity or context ones are kept as they are.
+func providerError(ticker entity.Ticker, err error, message string) error {
+	switch cause := errors.Cause(err).(type) {
+	case ResponseError:
+		switch cause.Kind {
+		case EmptyResult:
+			return entity.NewErrNothingFound(ticker)
+		case FormatChanged:
+			return errors.Wrap(entity.NewErrMalformedData(sourceName, cause.Error()), message)
+		default:
+			return errors.Wrap(entity.NewErrUpstreamFailure(sourceName, cause.Error()), message)
 		}
+	case entity.ErrUpstreamThrottled, entity.ErrNothingFound:
+		return errors.Wrap(err, message)
+	}
+	if cause := errors.Cause(err); cause == context.DeadlineExceeded || cause == context.Canceled {
+		return errors.Wrap(err, message)
 	}
 
-	return result, errors.Wrap(err, "unable to get current price")
+	return errors.Wrap(entity.NewErrUpstreamFailure(sourceName, err.Error()), message)
 }
@@ -1,10 +1,11 @@
 package googlefinance
 
 import (
+	"context"
 	"testing"
 	"time"
 	"org.alex859/stockprices/domain/entity"
-	"errors"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -20,10 +21,10 @@ func Test_GetHistoricalPrices_WHEN_ErrorFromGoogle_THEN_ShouldReturnError(t *tes
 	period := FromDateInterval(dateInterval)
 
 	noResponse := Response{}
-	client.On("FetchPrices", "LON", "ANP", period).Return(noResponse, errors.New("can't talk to google"))
+	client.On("FetchPrices", mock.Anything, "LON", "ANP", period).Return(noResponse, errors.New("can't talk to google"))
 
 	priceProvider := NewGoogleFinancePricesProvider(client, converter)
-	_, err := priceProvider.GetHistoricalPrices(ticker, dateInterval)
+	_, err := priceProvider.GetHistoricalPrices(context.Background(), ticker, dateInterval)
 
 	assert.Error(t, err)
 	converter.AssertNotCalled(t, mock.Anything)
@@ -40,14 +41,15 @@ func Test_GetHistoricalPrices_WHEN_ErrorConverting_THEN_ShouldReturnError(t *tes
 	period := FromDateInterval(dateInterval)
 
 	malformedResponse := Response{LastPrice: "AAA"}
-	client.On("FetchPrices", "LON", "ANP", period).Return(malformedResponse, nil)
+	client.On("FetchPrices", mock.Anything, "LON", "ANP", period).Return(malformedResponse, nil)
 	noConverted := entity.PriceHistory{}
 	converter.On("ConvertToPriceHistory", malformedResponse).Return(noConverted, errors.New("malformed input"))
 
 	priceProvider := NewGoogleFinancePricesProvider(client, converter)
-	_, err := priceProvider.GetHistoricalPrices(ticker, dateInterval)
+	_, err := priceProvider.GetHistoricalPrices(context.Background(), ticker, dateInterval)
 
 	assert.Error(t, err)
+	assert.Equal(t, entity.ParseFailure, entity.KindOf(err))
 }
 
 func Test_GetHistoricalPrices_WHEN_AllGood_THEN_ShouldReturnResult(t *testing.T) {
@@ -62,7 +64,7 @@ func Test_GetHistoricalPrices_WHEN_AllGood_THEN_ShouldReturnResult(t *testing.T)
 	period := FromDateInterval(dateInterval)
 
 	goodResponse := Response{LastPrice: "12.5"}
-	client.On("FetchPrices", "LON", "ANP", period).Return(goodResponse, nil)
+	client.On("FetchPrices", mock.Anything, "LON", "ANP", period).Return(goodResponse, nil)
 	converted := entity.PriceHistory{
 		TickerInfo: entity.TickerInfo{Name: "Anpario", Ticker: ticker, Currency: ""},
 		Prices: entity.PriceList{
@@ -82,7 +84,7 @@ func Test_GetHistoricalPrices_WHEN_AllGood_THEN_ShouldReturnResult(t *testing.T)
 			{Time: may9, Price: 12.5},
 		},
 	}
-	if result, err := priceProvider.GetHistoricalPrices(ticker, dateInterval); assert.NoError(t, err) {
+	if result, err := priceProvider.GetHistoricalPrices(context.Background(), ticker, dateInterval); assert.NoError(t, err) {
 		assert.Equal(t, expected, result)
 	}
 }
@@ -94,10 +96,10 @@ func Test_GetCurrentPrice_WHEN_ErrorFromGoogle_THEN_ShouldReturnError(t *testing
 	ticker := entity.Ticker{Symbol: "ANP", Market: "LON"}
 
 	noResponse := Response{}
-	client.On("FetchPrices", "LON", "ANP", mock.Anything).Return(noResponse, errors.New("can't talk to google"))
+	client.On("FetchPrices", mock.Anything, "LON", "ANP", mock.Anything).Return(noResponse, errors.New("can't talk to google"))
 
 	priceProvider := NewGoogleFinancePricesProvider(client, converter)
-	_, err := priceProvider.GetCurrentPrice(ticker)
+	_, err := priceProvider.GetCurrentPrice(context.Background(), ticker)
 
 	assert.Error(t, err)
 	converter.AssertNotCalled(t, mock.Anything)
@@ -110,12 +112,12 @@ func Test_GetCurrentPrice_WHEN_ErrorConverting_THEN_ShouldReturnError(t *testing
 	ticker := entity.Ticker{Symbol: "ANP", Market: "LON"}
 
 	malformedResponse := Response{LastPrice: "AAA"}
-	client.On("FetchPrices", "LON", "ANP", mock.Anything).Return(malformedResponse, nil)
+	client.On("FetchPrices", mock.Anything, "LON", "ANP", mock.Anything).Return(malformedResponse, nil)
 	noConverted := entity.CurrentPrice{}
 	converter.On("ConvertToCurrentPrice", malformedResponse).Return(noConverted, errors.New("malformed input"))
 
 	priceProvider := NewGoogleFinancePricesProvider(client, converter)
-	_, err := priceProvider.GetCurrentPrice(ticker)
+	_, err := priceProvider.GetCurrentPrice(context.Background(), ticker)
 
 	assert.Error(t, err)
 }
@@ -127,7 +129,7 @@ func Test_GetCurrentPrice_WHEN_AllGood_THEN_ShouldReturnResult(t *testing.T) {
 	ticker := entity.Ticker{Symbol: "ANP", Market: "LON"}
 
 	goodResponse := Response{LastPrice: "12.5"}
-	client.On("FetchPrices", "LON", "ANP", mock.Anything).Return(goodResponse, nil)
+	client.On("FetchPrices", mock.Anything, "LON", "ANP", mock.Anything).Return(goodResponse, nil)
 	converted := entity.CurrentPrice{
 		TickerInfo: entity.TickerInfo{Name: "Anpario", Ticker: ticker, Currency: ""},
 	}
@@ -135,7 +137,63 @@ func Test_GetCurrentPrice_WHEN_AllGood_THEN_ShouldReturnResult(t *testing.T) {
 
 	priceProvider := NewGoogleFinancePricesProvider(client, converter)
 
-	if result, err := priceProvider.GetCurrentPrice(ticker); assert.NoError(t, err) {
+	if result, err := priceProvider.GetCurrentPrice(context.Background(), ticker); assert.NoError(t, err) {
 		assert.Equal(t, converted, result)
 	}
 }
+
+func Test_GetHistoricalPrices_WHEN_EmptyResultFromGoogle_THEN_ShouldReturnNothingFound(t *testing.T) {
+	client := &MockPricesFetcher{}
+	converter := &MockResponseConverter{}
+
+	ticker := entity.Ticker{Symbol: "ANP", Market: "LON"}
+	may5, _ := time.Parse("02-01-2006", "05-05-2018")
+	may9, _ := time.Parse("02-01-2006", "09-05-2018")
+	dateInterval, _ := entity.NewDateInterval(may5, may9)
+
+	client.On("FetchPrices", mock.Anything, "LON", "ANP", mock.Anything).Return(Response{}, ResponseError{Kind: EmptyResult, Reason: "no chart data"})
+
+	priceProvider := NewGoogleFinancePricesProvider(client, converter)
+	_, err := priceProvider.GetHistoricalPrices(context.Background(), ticker, dateInterval)
+
+	assert.IsType(t, entity.ErrNothingFound{}, err)
+}
+
+func Test_GetCurrentPrice_WHEN_ConsentPageFromGoogle_THEN_ShouldReturnUpstreamFailure(t *testing.T) {
+	client := &MockPricesFetcher{}
+	converter := &MockResponseConverter{}
+
+	ticker := entity.Ticker{Symbol: "ANP", Market: "LON"}
+	client.On("FetchPrices", mock.Anything, "LON", "ANP", mock.Anything).Return(Response{}, ResponseError{Kind: ConsentPage, Reason: "captcha"})
+
+	priceProvider := NewGoogleFinancePricesProvider(client, converter)
+	_, err := priceProvider.GetCurrentPrice(context.Background(), ticker)
+
+	assert.IsType(t, entity.ErrUpstreamFailure{}, errors.Cause(err))
+	assert.Contains(t, err.Error(), "captcha")
+}
+
+func Test_GetHistoricalPrices_WHEN_ErrorsFromGoogle_THEN_ShouldTranslateThem(t *testing.T) {
+	ticker := entity.Ticker{Symbol: "ANP", Market: "LON"}
+	dateInterval, _ := entity.NewDateInterval(time.Now().AddDate(0, 0, -7), time.Now())
+	tests := []struct {
+		name string
+		err  error
+		want entity.ErrorKind
+	}{
+		{"format changed", errors.Wrap(ResponseError{Kind: FormatChanged, Reason: "no rows"}, "unable to get quotes"), entity.ParseFailure},
+		{"throttled", errors.Wrap(entity.NewErrUpstreamThrottled(sourceName, "429", 0), "unable to get quotes"), entity.Throttled},
+		{"transport", errors.New("connection reset by peer"), entity.UpstreamFailure},
+		{"deadline", errors.Wrap(context.DeadlineExceeded, "unable to talk to remote server"), entity.Timeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &MockPricesFetcher{}
+			client.On("FetchPrices", mock.Anything, "LON", "ANP", mock.Anything).Return(Response{}, tt.err)
+
+			_, err := NewGoogleFinancePricesProvider(client, &MockResponseConverter{}).GetHistoricalPrices(context.Background(), ticker, dateInterval)
+
+			assert.Equal(t, tt.want, entity.KindOf(err))
+		})
+	}
+}
@@ -1,7 +1,7 @@
 package googlefinance
 
 import (
-	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -17,11 +17,36 @@ type googleFinanceResponseConverter struct {
 // for testing
 var now = time.Now
 
-var dateLayouts = []string{
-	"Jan 2, 3:04 PM MST 2006",
-	"2 Jan, 15:04 GMT 2006",
+// lastPriceTimeLayouts are the layouts Google writes LastPriceTime with for the different markets, once the zone is removed.
+var lastPriceTimeLayouts = []string{
+	"Jan 2, 3:04 PM",
+	"Jan 2, 3:04:05 PM",
+	"Jan 2, 3:04 pm",
+	"Jan 2, 15:04",
+	"Jan 2, 15:04:05",
+	"2 Jan, 15:04",
+	"2 Jan, 15:04:05",
+	"2 Jan, 3:04 PM",
+	"2 Jan, 3:04 pm",
+	"2 Jan 15:04",
 }
 
+// zoneToken matches the zone at the end of LastPriceTime, an abbreviation like BST or an offset like GMT+1.
+var zoneToken = regexp.MustCompile(`^([A-Z]{2,5})([+-][0-9]{1,2}(:[0-9]{2})?)?$`)
+
+// zoneOffsets are the abbreviations of the exchange time zones in seconds east of UTC, for the ones not matching the exchange location.
+var zoneOffsets = map[string]int{
+	"GMT": 0, "UTC": 0, "BST": 3600, "WET": 0, "WEST": 3600, "CET": 3600, "CEST": 7200,
+	"EST": -5 * 3600, "EDT": -4 * 3600, "CST": -6 * 3600, "CDT": -5 * 3600,
+	"JST": 9 * 3600, "HKT": 8 * 3600, "SGT": 8 * 3600, "IST": 5*3600 + 1800,
+	"AEST": 10 * 3600, "AEDT": 11 * 3600,
+}
+
+// lastPriceTimeSkew is how far ahead of the fetch time a LastPriceTime can be before being taken for one of the year before.
+// Times within it are clocks out of sync, they are moved back to the fetch time. It is kept to minutes, as a time hours
+// ahead, e.g. 1 Jan read on 31 Dec, is the one of the year before.
+const lastPriceTimeSkew = 15 * time.Minute
+
 // NewGoogleFinanceResponseConverter creates a new googleFinanceResponseConverter.
 func NewGoogleFinanceResponseConverter() *googleFinanceResponseConverter {
 	return &googleFinanceResponseConverter{}
@@ -29,7 +54,7 @@ func NewGoogleFinanceResponseConverter() *googleFinanceResponseConverter {
 
 func (converter *googleFinanceResponseConverter) ConvertToPriceHistory(response Response) (result entity.PriceHistory, err error) {
 	var priceList entity.PriceList
-	if priceList, err = convertPriceRows(response.PricesRows); err == nil {
+	if priceList, err = convertPriceRows(response.PricesRows, entity.CalendarOf(response.Market).Location); err == nil {
 		result = entity.PriceHistory{
 			TickerInfo: convertTickerInfo(response),
 			Prices:     priceList,
@@ -42,34 +67,103 @@ func (converter *googleFinanceResponseConverter) ConvertToPriceHistory(response
 func (converter *googleFinanceResponseConverter) ConvertToCurrentPrice(response Response) (result entity.CurrentPrice, err error) {
 	var price float64
 	if price, err = convertPrice(response.LastPrice); err == nil {
+		fetchedAt := response.FetchedAt
+		if fetchedAt.IsZero() {
+			fetchedAt = now()
+		}
 		var lastTime time.Time
-		currentYear := now().Year()
-		if lastTime, err = readTime(fmt.Sprintf("%s %v", response.LastPriceTime, currentYear)); err == nil {
+		if lastTime, err = readLastPriceTime(response.LastPriceTime, entity.CalendarOf(response.Market).Location, fetchedAt); err == nil {
 			result = entity.CurrentPrice{
 				TickerInfo: convertTickerInfo(response),
 				Price:      price,
-				Time:       lastTime.In(time.UTC),
+				Time:       lastTime,
 			}
 		}
 	}
 	return result, errors.Wrap(err, "error converting to current price")
 }
 
-func readTime(str string) (result time.Time, err error) {
-	for _, layout := range dateLayouts {
-		result, err = time.Parse(layout, str)
-		if err == nil {
-			return result, err
+// readLastPriceTime reads times like "6 Sep, 15:04 BST" or "Sep 6, 2:04 PM GMT-4", which come without a year.
+// The year is the one making the time the latest not after the fetch time, so a quote of 31 December read on 1 January is from last year.
+// The zone is resolved to the exchange location when it is one of its abbreviations, the exchange location is used when missing.
+// The result is in the exchange location.
+func readLastPriceTime(str string, location *time.Location, fetchedAt time.Time) (time.Time, error) {
+	str = strings.TrimSpace(str)
+	if i := strings.Index(str, "·"); i >= 0 {
+		str = strings.TrimSpace(str[:i])
+	}
+	if i := strings.Index(str, ": "); i >= 0 {
+		// e.g. "Closed: Sep 6, 4:30 PM GMT+1"
+		str = str[i+2:]
+	}
+
+	zone := ""
+	if i := strings.LastIndex(str, " "); i >= 0 && zoneToken.MatchString(str[i+1:]) {
+		str, zone = str[:i], str[i+1:]
+	}
+
+	var clock time.Time
+	var err error
+	for _, layout := range lastPriceTimeLayouts {
+		if clock, err = time.Parse(layout, str); err == nil {
+			break
 		}
 	}
+	if err != nil {
+		return time.Time{}, errors.Errorf("unknown last price time format %q", str)
+	}
 
-	return result, err
+	fetchedIn := fetchedAt.In(location)
+	for year := fetchedIn.Year() + 1; year >= fetchedIn.Year()-8; year-- {
+		candidate := time.Date(year, clock.Month(), clock.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, location)
+		if candidate.Day() != clock.Day() {
+			// 29 February in a year without it
+			continue
+		}
+		candidate = time.Date(year, clock.Month(), clock.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, zoneLocation(zone, candidate, location)).In(location)
+		if candidate.After(fetchedAt.Add(lastPriceTimeSkew)) {
+			continue
+		}
+		if candidate.After(fetchedAt) {
+			candidate = fetchedIn
+		}
+		return candidate, nil
+	}
+	return time.Time{}, errors.Errorf("unable to find the year of last price time %q", str)
+}
+
+// zoneLocation resolves a zone abbreviation or offset to a location, given the time it was used at in the exchange location.
+func zoneLocation(zone string, t time.Time, location *time.Location) *time.Location {
+	if name, _ := t.Zone(); zone == "" || zone == name {
+		return location
+	}
+
+	match := zoneToken.FindStringSubmatch(zone)
+	offset, known := zoneOffsets[match[1]]
+	if !known {
+		return location
+	}
+	if match[2] != "" {
+		parts := strings.SplitN(match[2][1:], ":", 2)
+		hours, _ := strconv.Atoi(parts[0])
+		minutes := 0
+		if len(parts) == 2 {
+			minutes, _ = strconv.Atoi(parts[1])
+		}
+		extra := hours*3600 + minutes*60
+		if match[2][0] == '-' {
+			extra = -extra
+		}
+		offset += extra
+	}
+	return time.FixedZone(zone, offset)
 }
 
-func convertPriceRows(priceRows []PriceRow) (entity.PriceList, error) {
+// convertPriceRows reads the rows with their times in the exchange location, so that their days are the exchange-local ones.
+func convertPriceRows(priceRows []PriceRow, location *time.Location) (entity.PriceList, error) {
 	result := make(entity.PriceList, len(priceRows))
 	for i, p := range priceRows {
-		if pp, err := convertPriceRow(p); err == nil {
+		if pp, err := convertPriceRow(p, location); err == nil {
 			result[i] = pp
 		} else {
 			return result, errors.Wrap(err, "error converting price rows")
@@ -79,14 +173,14 @@ func convertPriceRows(priceRows []PriceRow) (entity.PriceList, error) {
 	return result, nil
 }
 
-func convertPriceRow(priceRow PriceRow) (result entity.PricePoint, err error) {
+func convertPriceRow(priceRow PriceRow, location *time.Location) (result entity.PricePoint, err error) {
 	var p float64
 	if p, err = convertPrice(priceRow.Price); err == nil {
 		var t float64
 		if t, err = strconv.ParseFloat(priceRow.Time, 64); err == nil {
 			result = entity.PricePoint{
 				Price: p,
-				Time:  time.Unix(60*(int64(t)), 0).In(time.UTC),
+				Time:  time.Unix(60*(int64(t)), 0).In(location),
 			}
 		}
 	}
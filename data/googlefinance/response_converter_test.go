@@ -60,14 +60,14 @@ var date3, _ = time.Parse("02-01-2006T15:04:05.000Z", "06-09-2018T14:04:00.000Z"
 var goodPriceHistory = entity.PriceHistory{
 	TickerInfo: entity.TickerInfo{Name:"Anpario", Currency:"GBX", Ticker:entity.Ticker{Market:"LON", Symbol:"ANP"}},
 	Prices:entity.PriceList{
-		{Price:20.25, Time: date1},
-		{Price:20.26, Time: date2},
+		{Price:20.25, Time: date1.In(entity.CalendarOf("LON").Location)},
+		{Price:20.26, Time: date2.In(entity.CalendarOf("LON").Location)},
 	},
 }
 
 var goodCurrentPrice = entity.CurrentPrice{
 	TickerInfo: entity.TickerInfo{Name:"Anpario", Currency:"GBX", Ticker:entity.Ticker{Market:"LON", Symbol:"ANP"}},
-	Time:       date3,
+	Time:       date3.In(entity.CalendarOf("LON").Location),
 	Price:      12.25,
 }
 
@@ -104,7 +104,7 @@ func Test_googleFinanceResponseConverter_ConvertToPriceHistory(t *testing.T) {
 
 func Test_googleFinanceResponseConverter_ConvertToCurrentPrice(t *testing.T) {
 	now = func() time.Time {
-		return date1
+		return date3.Add(time.Hour)
 	}
 	type args struct {
 		response Response
@@ -136,3 +136,39 @@ func Test_googleFinanceResponseConverter_ConvertToCurrentPrice(t *testing.T) {
 		})
 	}
 }
+
+func Test_readLastPriceTime(t *testing.T) {
+	london := entity.CalendarOf("LON").Location
+	newYork := entity.CalendarOf("NASDAQ").Location
+	tests := []struct {
+		name      string
+		str       string
+		location  *time.Location
+		fetchedAt time.Time
+		want      time.Time
+	}{
+		{"Same year", "6 Sep, 15:04 BST", london, time.Date(2018, time.September, 7, 9, 0, 0, 0, time.UTC), time.Date(2018, time.September, 6, 15, 4, 0, 0, london)},
+		{"Last year when read in January", "31 Dec, 16:30 GMT", london, time.Date(2019, time.January, 1, 9, 0, 0, 0, time.UTC), time.Date(2018, time.December, 31, 16, 30, 0, 0, london)},
+		{"Abbreviation of the exchange location", "Sep 6, 2:04 PM EDT", newYork, time.Date(2018, time.September, 6, 20, 0, 0, 0, time.UTC), time.Date(2018, time.September, 6, 14, 4, 0, 0, newYork)},
+		{"Offset", "Sep 6, 2:04 PM GMT-4", newYork, time.Date(2018, time.September, 6, 20, 0, 0, 0, time.UTC), time.Date(2018, time.September, 6, 14, 4, 0, 0, newYork)},
+		{"Seconds and closed prefix", "Closed: Sep 6, 4:00:01 PM EDT · Disclaimer", newYork, time.Date(2018, time.September, 7, 0, 0, 0, 0, time.UTC), time.Date(2018, time.September, 6, 16, 0, 1, 0, newYork)},
+		{"No zone is exchange time", "6 Sep, 15:04", london, time.Date(2018, time.September, 7, 9, 0, 0, 0, time.UTC), time.Date(2018, time.September, 6, 15, 4, 0, 0, london)},
+		{"Slightly ahead is fetch time", "6 Sep, 15:04 BST", london, time.Date(2018, time.September, 6, 14, 0, 0, 0, time.UTC), time.Date(2018, time.September, 6, 15, 0, 0, 0, london)},
+		{"Last year when hours ahead", "1 Jan, 10:00 GMT", london, time.Date(2018, time.December, 31, 11, 0, 0, 0, time.UTC), time.Date(2018, time.January, 1, 10, 0, 0, 0, london)},
+		{"Leap day", "29 Feb, 16:30 GMT", london, time.Date(2017, time.March, 1, 9, 0, 0, 0, time.UTC), time.Date(2016, time.February, 29, 16, 30, 0, 0, london)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readLastPriceTime(tt.str, tt.location, tt.fetchedAt)
+			if err != nil {
+				t.Fatalf("readLastPriceTime() error = %v", err)
+			}
+			if !got.Equal(tt.want) || got.Location() != tt.location {
+				t.Errorf("readLastPriceTime() = %v, want %v", got, tt.want)
+			}
+			if got.After(tt.fetchedAt) {
+				t.Errorf("readLastPriceTime() = %v is after the fetch time %v", got, tt.fetchedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package googlefinance
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy tells how many times and how far apart transient failures are retried.
+// The delay before attempt n is a random value up to BaseDelay * 2^(n-1), capped at MaxDelay,
+// unless Google asks for a longer wait through a Retry-After header.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy is used when no other policy is given to NewDefaultPricesFetcher.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 500 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+// NoRetry makes a single attempt per request.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// backoff returns the delay to wait before the given retry, starting from 1.
+func (policy RetryPolicy) backoff(retry int, random func() float64) time.Duration {
+	ceiling := policy.BaseDelay
+	for i := 1; i < retry && ceiling < policy.MaxDelay; i++ {
+		ceiling *= 2
+	}
+	if policy.MaxDelay > 0 && ceiling > policy.MaxDelay {
+		ceiling = policy.MaxDelay
+	}
+
+	return time.Duration(random() * float64(ceiling))
+}
+
+// FetcherOption configures a defaultPricesFetcher.
+type FetcherOption func(*defaultPricesFetcher)
+
+// WithRetryPolicy sets the policy used to retry transient failures.
+func WithRetryPolicy(policy RetryPolicy) FetcherOption {
+	return func(fetcher *defaultPricesFetcher) {
+		if policy.MaxAttempts < 1 {
+			policy.MaxAttempts = 1
+		}
+		fetcher.retryPolicy = policy
+	}
+}
+
+// responseClass tells what a response from Google means for the caller.
+type responseClass int
+
+const (
+	success responseClass = iota
+	// throttled means Google asked us to slow down, it is worth retrying later.
+	throttled
+	// blocked means Google is refusing us, e.g. with a captcha page, retrying straight away will not help.
+	blocked
+	// serverError means Google had a problem of its own, it is worth retrying.
+	serverError
+	// clientError means the request itself is wrong, retrying will not help.
+	clientError
+)
+
+func (class responseClass) String() string {
+	switch class {
+	case success:
+		return "success"
+	case throttled:
+		return "throttled"
+	case blocked:
+		return "blocked"
+	case serverError:
+		return "server error"
+	case clientError:
+		return "client error"
+	default:
+		return "unknown"
+	}
+}
+
+func (class responseClass) retryable() bool {
+	return class == throttled || class == serverError
+}
+
+// classifyResponse looks at the status code and at the body, Google serves its "unusual traffic" page with any status.
+func classifyResponse(statusCode int, body string) responseClass {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return throttled
+	case statusCode == http.StatusForbidden || looksLikeCaptchaPage(body):
+		return blocked
+	case statusCode >= 500:
+		return serverError
+	case statusCode >= 400:
+		return clientError
+	default:
+		return success
+	}
+}
+
+// parseRetryAfter reads a Retry-After header, given either in seconds or as an HTTP date.
+// It returns zero when the header is missing or cannot be read.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+// sleepContext waits for the given duration or until the context is done, whatever comes first.
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// statusError is returned for responses that are not successful.
+type statusError struct {
+	class      responseClass
+	statusCode int
+	retryAfter time.Duration
+}
+
+func (err statusError) Error() string {
+	return fmt.Sprintf("google answered %d (%s)", err.statusCode, err.class)
+}
+
+func defaultRandom() float64 {
+	return rand.Float64()
+}
@@ -0,0 +1,175 @@
+package googlefinance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"org.alex859/stockprices/domain/entity"
+)
+
+// testFetcher creates a fetcher recording its sleeps instead of waiting.
+func testFetcher(client *http.Client, policy RetryPolicy, sleeps *[]time.Duration) *defaultPricesFetcher {
+	fetcher := NewDefaultPricesFetcher(client, WithRetryPolicy(policy))
+	fetcher.random = func() float64 { return 1 }
+	fetcher.sleep = func(ctx context.Context, duration time.Duration) error {
+		*sleeps = append(*sleeps, duration)
+		return ctx.Err()
+	}
+	return fetcher
+}
+
+// answers replies with the given status codes in turn, the last one being repeated.
+func answers(calls *int32, statusCodes ...int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		call := int(atomic.AddInt32(calls, 1)) - 1
+		if call >= len(statusCodes) {
+			call = len(statusCodes) - 1
+		}
+		w.WriteHeader(statusCodes[call])
+		w.Write([]byte("page"))
+	}
+}
+
+func Test_classifyResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		want       responseClass
+	}{
+		{"ok", 200, "<html></html>", success},
+		{"too many requests", 429, "", throttled},
+		{"forbidden", 403, "", blocked},
+		{"unusual traffic page", 200, "Our systems have detected unusual traffic from your computer network.", blocked},
+		{"sorry page", 302, `<a href="https://www.google.com/sorry/index?continue=">`, blocked},
+		{"server error", 503, "", serverError},
+		{"not found", 404, "", clientError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyResponse(tt.statusCode, tt.body))
+		})
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2018, time.May, 5, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 120*time.Second, parseRetryAfter("120", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter("Sat, 05 May 2018 12:00:30 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("Sat, 05 May 2018 11:00:00 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+}
+
+func Test_backoff_WHEN_Retrying_THEN_GrowExponentiallyUpToMax(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	full := func() float64 { return 1 }
+
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1, full))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2, full))
+	assert.Equal(t, 800*time.Millisecond, policy.backoff(4, full))
+	assert.Equal(t, time.Second, policy.backoff(8, full))
+	assert.Equal(t, 50*time.Millisecond, policy.backoff(1, func() float64 { return 0.5 }))
+}
+
+func Test_htmlFrom_WHEN_ServerErrorThenOK_THEN_RetryAndReturnPage(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(answers(&calls, 500, 502, 200))
+	defer server.Close()
+	var sleeps []time.Duration
+
+	page, err := testFetcher(server.Client(), DefaultRetryPolicy, &sleeps).htmlFrom(context.Background(), server.URL)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "page", page)
+	}
+	assert.Equal(t, int32(3), calls)
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, time.Second}, sleeps)
+}
+
+func Test_htmlFrom_WHEN_AlwaysThrottled_THEN_ReturnThrottledErrorAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(answers(&calls, 429))
+	defer server.Close()
+	var sleeps []time.Duration
+
+	_, err := testFetcher(server.Client(), DefaultRetryPolicy, &sleeps).htmlFrom(context.Background(), server.URL)
+
+	assert.IsType(t, entity.ErrUpstreamThrottled{}, errors.Cause(err))
+	assert.Equal(t, int32(3), calls)
+	assert.Len(t, sleeps, 2)
+}
+
+func Test_htmlFrom_WHEN_RetryAfter_THEN_WaitAsAsked(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(429)
+			return
+		}
+		w.Write([]byte("page"))
+	}))
+	defer server.Close()
+	var sleeps []time.Duration
+
+	_, err := testFetcher(server.Client(), DefaultRetryPolicy, &sleeps).htmlFrom(context.Background(), server.URL)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{2 * time.Second}, sleeps)
+}
+
+func Test_htmlFrom_WHEN_RetryAfterTooLong_THEN_GiveUpWithWait(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(429)
+	}))
+	defer server.Close()
+	var sleeps []time.Duration
+
+	_, err := testFetcher(server.Client(), DefaultRetryPolicy, &sleeps).htmlFrom(context.Background(), server.URL)
+
+	if throttledErr, ok := errors.Cause(err).(entity.ErrUpstreamThrottled); assert.True(t, ok, "unexpected error %v", err) {
+		assert.Equal(t, time.Hour, throttledErr.RetryAfter)
+	}
+	assert.Equal(t, int32(1), calls)
+	assert.Empty(t, sleeps)
+}
+
+func Test_htmlFrom_WHEN_CaptchaPage_THEN_ReturnThrottledErrorWithoutRetrying(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte("<html>Our systems have detected unusual traffic from your computer network.</html>"))
+	}))
+	defer server.Close()
+	var sleeps []time.Duration
+
+	_, err := testFetcher(server.Client(), DefaultRetryPolicy, &sleeps).htmlFrom(context.Background(), server.URL)
+
+	assert.IsType(t, entity.ErrUpstreamThrottled{}, errors.Cause(err))
+	assert.Equal(t, int32(1), calls)
+}
+
+func Test_htmlFrom_WHEN_ClientError_THEN_DoNotRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(answers(&calls, 404))
+	defer server.Close()
+	var sleeps []time.Duration
+
+	_, err := testFetcher(server.Client(), DefaultRetryPolicy, &sleeps).htmlFrom(context.Background(), server.URL)
+
+	if assert.Error(t, err) {
+		assert.IsType(t, statusError{}, errors.Cause(err))
+	}
+	assert.Equal(t, int32(1), calls)
+}
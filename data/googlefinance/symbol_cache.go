@@ -0,0 +1,144 @@
+package googlefinance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultDataMidTTL is how long a resolved data-mid is trusted, they hardly ever change for a ticker.
+	DefaultDataMidTTL = 30 * 24 * time.Hour
+	// DefaultEiTTL is how long an ei token is reused, Google issues a new one with every page.
+	DefaultEiTTL = 10 * time.Minute
+)
+
+// A data-mid resolved for a ticker, as kept in memory and on disk.
+type cachedDataMid struct {
+	DataMid    string    `json:"dataMid"`
+	ResolvedAt time.Time `json:"resolvedAt"`
+}
+
+// Remembers the result of symbol searches, so that a chart can be fetched without searching again.
+// The data-mid of a ticker is stable and kept for long, optionally persisted to a JSON file.
+// The ei token is not bound to a ticker and expires quickly, it is shared across tickers and only kept in memory.
+type symbolCache struct {
+	mutex      sync.Mutex
+	dataMids   map[string]cachedDataMid
+	ei         string
+	eiAt       time.Time
+	dataMidTTL time.Duration
+	eiTTL      time.Duration
+	file       string
+	now        func() time.Time
+}
+
+// NewSymbolCache creates a cache keeping data-mids for dataMidTTL and ei tokens for eiTTL.
+// When file is not empty, data-mids are loaded from it and written back to it whenever a new one is resolved.
+func NewSymbolCache(dataMidTTL time.Duration, eiTTL time.Duration, file string) *symbolCache {
+	cache := &symbolCache{
+		dataMids:   map[string]cachedDataMid{},
+		dataMidTTL: dataMidTTL,
+		eiTTL:      eiTTL,
+		file:       file,
+		now:        time.Now,
+	}
+	if file != "" {
+		if err := cache.load(); err != nil {
+			log.Printf("Ignoring symbol cache file %s. Error: %s", file, err)
+		}
+	}
+
+	return cache
+}
+
+// WithSymbolCache makes the fetcher skip the symbol search when the cache can answer.
+func WithSymbolCache(cache *symbolCache) FetcherOption {
+	return func(fetcher *defaultPricesFetcher) {
+		fetcher.symbols = cache
+	}
+}
+
+// get returns the data-mid and ei to use for the ticker, found is false unless both are still valid.
+func (cache *symbolCache) get(ticker string) (dataMid string, ei string, found bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := cache.now()
+	cached, ok := cache.dataMids[ticker]
+	if !ok || now.Sub(cached.ResolvedAt) > cache.dataMidTTL {
+		return "", "", false
+	}
+	if cache.ei == "" || now.Sub(cache.eiAt) > cache.eiTTL {
+		return "", "", false
+	}
+
+	return cached.DataMid, cache.ei, true
+}
+
+// put records the result of a symbol search.
+func (cache *symbolCache) put(ticker string, dataMid string, ei string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := cache.now()
+	cache.ei, cache.eiAt = ei, now
+
+	previous, known := cache.dataMids[ticker]
+	cache.dataMids[ticker] = cachedDataMid{DataMid: dataMid, ResolvedAt: now}
+	if known && previous.DataMid == dataMid && now.Sub(previous.ResolvedAt) < cache.dataMidTTL/2 {
+		return
+	}
+	cache.save()
+}
+
+// invalidate forgets what is known about the ticker and the ei token, they may be the reason for a failure.
+func (cache *symbolCache) invalidate(ticker string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.ei = ""
+	if _, known := cache.dataMids[ticker]; known {
+		delete(cache.dataMids, ticker)
+		cache.save()
+	}
+}
+
+func (cache *symbolCache) load() error {
+	content, err := ioutil.ReadFile(cache.file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "unable to read file")
+	}
+
+	return errors.Wrap(json.Unmarshal(content, &cache.dataMids), "unable to decode file")
+}
+
+// save writes the data-mids to the cache file, failures are only logged as the cache still works in memory.
+// The file is replaced atomically so that concurrent readers never see a partial one.
+func (cache *symbolCache) save() {
+	if cache.file == "" {
+		return
+	}
+
+	content, err := json.MarshalIndent(cache.dataMids, "", "  ")
+	if err == nil {
+		tmp := cache.file + ".tmp"
+		if err = os.MkdirAll(filepath.Dir(cache.file), 0755); err == nil {
+			if err = ioutil.WriteFile(tmp, content, 0644); err == nil {
+				err = os.Rename(tmp, cache.file)
+			}
+		}
+	}
+	if err != nil {
+		log.Printf("Unable to save symbol cache file %s. Error: %s", cache.file, err)
+	}
+}
@@ -0,0 +1,121 @@
+package googlefinance
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const searchPage = `<html><body>
+<form id="tophf"><input name="ei" value="EI123"></form>
+<div id="rso"><div><div data-mid="/m/07zm_3b"></div></div></div>
+</body></html>`
+
+// fakeGoogle serves search pages and charts in place of Google, counting the requests it gets.
+type fakeGoogle struct {
+	searches int
+	charts   int
+	chart    string
+}
+
+func (google *fakeGoogle) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	if strings.HasPrefix(req.URL.Path, "/search") {
+		google.searches++
+		recorder.WriteString(searchPage)
+	} else {
+		google.charts++
+		recorder.WriteString(google.chart)
+	}
+	return recorder.Result(), nil
+}
+
+func newFakeGoogle(t *testing.T) *fakeGoogle {
+	chart, err := ioutil.ReadFile("testdata/google_finance_day_all_good")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &fakeGoogle{chart: string(chart)}
+}
+
+func Test_symbolCache_WHEN_Expired_THEN_NotFound(t *testing.T) {
+	now := time.Date(2018, time.May, 5, 12, 0, 0, 0, time.UTC)
+	cache := NewSymbolCache(time.Hour, time.Minute, "")
+	cache.now = func() time.Time { return now }
+	cache.put("LON:ANP", "/m/07zm_3b", "EI123")
+
+	dataMid, ei, found := cache.get("LON:ANP")
+	assert.True(t, found)
+	assert.Equal(t, "/m/07zm_3b", dataMid)
+	assert.Equal(t, "EI123", ei)
+
+	now = now.Add(2 * time.Minute)
+	_, _, found = cache.get("LON:ANP")
+	assert.False(t, found, "ei should have expired")
+
+	cache.put("LON:SDRY", "/m/0abc", "EI456")
+	_, ei, found = cache.get("LON:ANP")
+	assert.True(t, found, "ei should be shared across tickers")
+	assert.Equal(t, "EI456", ei)
+
+	now = now.Add(2 * time.Hour)
+	_, _, found = cache.get("LON:ANP")
+	assert.False(t, found, "data-mid should have expired")
+}
+
+func Test_symbolCache_WHEN_File_THEN_DataMidsSurviveRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "symbols")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "cache", "symbols.json")
+
+	NewSymbolCache(time.Hour, time.Minute, file).put("LON:ANP", "/m/07zm_3b", "EI123")
+	restarted := NewSymbolCache(time.Hour, time.Minute, file)
+
+	assert.Equal(t, "/m/07zm_3b", restarted.dataMids["LON:ANP"].DataMid)
+	_, _, found := restarted.get("LON:ANP")
+	assert.False(t, found, "ei should not be persisted")
+
+	restarted.invalidate("LON:ANP")
+	assert.Empty(t, NewSymbolCache(time.Hour, time.Minute, file).dataMids)
+}
+
+func Test_FetchPrices_WHEN_SymbolCached_THEN_SkipSearch(t *testing.T) {
+	google := newFakeGoogle(t)
+	fetcher := NewDefaultPricesFetcher(&http.Client{Transport: google}, WithSymbolCache(NewSymbolCache(time.Hour, time.Minute, "")))
+
+	for i := 0; i < 3; i++ {
+		_, err := fetcher.FetchPrices(context.Background(), "LON", "ANP", OneDay)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, google.searches)
+	assert.Equal(t, 3, google.charts)
+}
+
+func Test_FetchPrices_WHEN_CachedSymbolFails_THEN_SearchAgainOnce(t *testing.T) {
+	google := newFakeGoogle(t)
+	cache := NewSymbolCache(time.Hour, time.Minute, "")
+	cache.put("LON:ANP", "/m/stale", "EI000")
+	fetcher := NewDefaultPricesFetcher(&http.Client{Transport: google}, WithSymbolCache(cache))
+	chart, _ := ioutil.ReadFile("testdata/google_finance_day_unexpected_order")
+	google.chart = string(chart)
+
+	_, err := fetcher.FetchPrices(context.Background(), "LON", "ANP", OneDay)
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, google.searches)
+	assert.Equal(t, 2, google.charts)
+	dataMid, _, _ := cache.get("LON:ANP")
+	assert.Equal(t, "/m/07zm_3b", dataMid)
+}
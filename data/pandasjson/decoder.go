@@ -0,0 +1,154 @@
+package pandasjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"org.alex859/stockprices/domain/entity"
+)
+
+// Columns names the columns holding the date and the prices.
+// Date and Close are required, bars are read too when the frame has the Open, High and Low columns, Volume being optional.
+type Columns struct {
+	Date   string
+	Open   string
+	High   string
+	Low    string
+	Close  string
+	Volume string
+}
+
+// DefaultColumns are the column names written by our notebooks.
+var DefaultColumns = Columns{Date: "Date", Open: "Open", High: "High", Low: "Low", Close: "Close", Volume: "Volume"}
+
+// ErrColumnMismatch is returned when a column does not have the same row keys as the date column.
+type ErrColumnMismatch struct {
+	Column     string
+	Missing    []string
+	Unexpected []string
+}
+
+func (err ErrColumnMismatch) Error() string {
+	return fmt.Sprintf("column %s does not match the date column: missing rows [%s], unexpected rows [%s]",
+		err.Column, strings.Join(err.Missing, ","), strings.Join(err.Unexpected, ","))
+}
+
+// Decoder reads the column oriented JSON written by pandas DataFrame.to_json(orient="columns"),
+// i.e. an object of columns, each one an object of row key to value.
+// Dates are expected in epoch milliseconds, the pandas default.
+type Decoder struct {
+	columns Columns
+}
+
+// NewDecoder creates a new Decoder reading the given columns.
+func NewDecoder(columns Columns) *Decoder {
+	return &Decoder{columns: columns}
+}
+
+// Decode reads a PriceList from the reader, ordered by time.
+// All the columns in the frame, not just the ones we read, must have the same row keys, otherwise an ErrColumnMismatch is returned.
+func (decoder *Decoder) Decode(reader io.Reader) (entity.PriceList, error) {
+	history, err := decoder.DecodeHistory(reader)
+	return history.Prices, err
+}
+
+// DecodeHistory reads the prices from the reader like Decode, along with bars when the frame has their columns.
+// Missing open, high or low values default to the close price, missing volumes to zero.
+func (decoder *Decoder) DecodeHistory(reader io.Reader) (entity.PriceHistory, error) {
+	var frame map[string]map[string]*float64
+	if err := json.NewDecoder(reader).Decode(&frame); err != nil {
+		return entity.PriceHistory{}, errors.Wrap(err, "unable to decode frame")
+	}
+
+	dates, ok := frame[decoder.columns.Date]
+	if !ok {
+		return entity.PriceHistory{}, errors.Errorf("missing date column %q", decoder.columns.Date)
+	}
+	closes, ok := frame[decoder.columns.Close]
+	if !ok {
+		return entity.PriceHistory{}, errors.Errorf("missing close column %q", decoder.columns.Close)
+	}
+
+	if err := checkRowKeys(frame, decoder.columns.Date); err != nil {
+		return entity.PriceHistory{}, err
+	}
+
+	opens, highs, lows := frame[decoder.columns.Open], frame[decoder.columns.High], frame[decoder.columns.Low]
+	hasBars := opens != nil && highs != nil && lows != nil
+	volumes := frame[decoder.columns.Volume]
+
+	var result entity.PriceHistory
+	result.Prices = make(entity.PriceList, 0, len(dates))
+	for key, date := range dates {
+		closePrice := closes[key]
+		if date == nil || closePrice == nil {
+			return entity.PriceHistory{}, errors.Errorf("missing value in row %s", key)
+		}
+		t := time.Unix(0, int64(*date)*int64(time.Millisecond)).In(time.UTC)
+		result.Prices = append(result.Prices, entity.PricePoint{Price: *closePrice, Time: t})
+		if hasBars {
+			result.Bars = append(result.Bars, entity.Bar{
+				Time:   t,
+				Open:   valueOr(opens[key], *closePrice),
+				High:   valueOr(highs[key], *closePrice),
+				Low:    valueOr(lows[key], *closePrice),
+				Close:  *closePrice,
+				Volume: valueOr(volumes[key], 0),
+			})
+		}
+	}
+
+	sort.Slice(result.Prices, func(i, j int) bool {
+		return result.Prices[i].Time.Before(result.Prices[j].Time)
+	})
+	sort.Slice(result.Bars, func(i, j int) bool {
+		return result.Bars[i].Time.Before(result.Bars[j].Time)
+	})
+	return result, nil
+}
+
+func valueOr(value *float64, fallback float64) float64 {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
+
+// checkRowKeys makes sure every column in the frame has the same row keys as the reference one.
+func checkRowKeys(frame map[string]map[string]*float64, reference string) error {
+	names := make([]string, 0, len(frame))
+	for name := range frame {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == reference {
+			continue
+		}
+
+		mismatch := ErrColumnMismatch{Column: name}
+		for key := range frame[reference] {
+			if _, ok := frame[name][key]; !ok {
+				mismatch.Missing = append(mismatch.Missing, key)
+			}
+		}
+		for key := range frame[name] {
+			if _, ok := frame[reference][key]; !ok {
+				mismatch.Unexpected = append(mismatch.Unexpected, key)
+			}
+		}
+
+		if len(mismatch.Missing) > 0 || len(mismatch.Unexpected) > 0 {
+			sort.Strings(mismatch.Missing)
+			sort.Strings(mismatch.Unexpected)
+			return mismatch
+		}
+	}
+	return nil
+}
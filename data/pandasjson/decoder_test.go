@@ -0,0 +1,85 @@
+package pandasjson
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"org.alex859/stockprices/domain/entity"
+)
+
+func Test_Decode_AllGood(t *testing.T) {
+	file, _ := os.Open("testdata/google_price_response.json")
+	defer file.Close()
+
+	result, err := NewDecoder(DefaultColumns).Decode(file)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 6, len(result))
+		assert.Equal(t, entity.PricePoint{Price: 163.5, Time: time.Date(2013, time.September, 3, 17, 30, 0, 0, time.UTC)}, result[0])
+		assert.Equal(t, entity.PricePoint{Price: 172.5, Time: time.Date(2013, time.September, 10, 17, 30, 0, 0, time.UTC)}, result[5])
+	}
+}
+
+func Test_Decode_Mismatch(t *testing.T) {
+	file, _ := os.Open("testdata/google_price_response_mismatch.json")
+	defer file.Close()
+
+	_, err := NewDecoder(DefaultColumns).Decode(file)
+
+	assert.Equal(t, ErrColumnMismatch{Column: "Close", Missing: []string{"5"}}, errors.Cause(err))
+}
+
+func Test_Decode_UnexpectedRow(t *testing.T) {
+	frame := `{"Date": {"0": 1378229400000}, "Close": {"0": 163.5}, "Timestamp": {"0": 22970430, "1": 22971870}}`
+
+	_, err := NewDecoder(DefaultColumns).Decode(strings.NewReader(frame))
+
+	assert.Equal(t, ErrColumnMismatch{Column: "Timestamp", Unexpected: []string{"1"}}, errors.Cause(err))
+}
+
+func Test_Decode_OrderedByDate(t *testing.T) {
+	frame := `{"Date": {"0": 1378315800000, "1": 1378229400000}, "Close": {"0": 166.0, "1": 163.5}}`
+
+	result, err := NewDecoder(DefaultColumns).Decode(strings.NewReader(frame))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, entity.PriceList{
+			{Price: 163.5, Time: time.Date(2013, time.September, 3, 17, 30, 0, 0, time.UTC)},
+			{Price: 166.0, Time: time.Date(2013, time.September, 4, 17, 30, 0, 0, time.UTC)},
+		}, result)
+	}
+}
+
+func Test_DecodeHistory_WHEN_BarColumns_THEN_ReturnBars(t *testing.T) {
+	frame := `{"Date": {"0": 1378229400000}, "Open": {"0": 160}, "High": {"0": 165}, "Low": {"0": null}, "Close": {"0": 163.5}}`
+
+	result, err := NewDecoder(DefaultColumns).DecodeHistory(strings.NewReader(frame))
+
+	if assert.NoError(t, err) {
+		at := time.Date(2013, time.September, 3, 17, 30, 0, 0, time.UTC)
+		assert.Equal(t, entity.PriceList{{Price: 163.5, Time: at}}, result.Prices)
+		assert.Equal(t, entity.BarList{{Time: at, Open: 160, High: 165, Low: 163.5, Close: 163.5}}, result.Bars)
+	}
+}
+
+func Test_Decode_MissingColumn(t *testing.T) {
+	_, err := NewDecoder(DefaultColumns).Decode(strings.NewReader(`{"Date": {"0": 1378229400000}}`))
+
+	assert.Error(t, err)
+}
+
+func Test_Decode_NullValue(t *testing.T) {
+	_, err := NewDecoder(DefaultColumns).Decode(strings.NewReader(`{"Date": {"0": 1378229400000}, "Close": {"0": null}}`))
+
+	assert.Error(t, err)
+}
+
+func Test_Decode_NotJSON(t *testing.T) {
+	_, err := NewDecoder(DefaultColumns).Decode(strings.NewReader(`Date,Close`))
+
+	assert.Error(t, err)
+}
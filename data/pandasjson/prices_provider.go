@@ -0,0 +1,68 @@
+package pandasjson
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"org.alex859/stockprices/domain/entity"
+)
+
+// Reads prices from pandas JSON exports on the local file system, one file per ticker.
+type pandasJSONPricesProvider struct {
+	dir      string
+	fileName func(ticker entity.Ticker) string
+	decoder  *Decoder
+}
+
+// NewPandasJSONPricesProvider creates a new provider reading files from dir.
+// fileName maps a ticker to its file name in dir, DefaultFileName is used when nil.
+func NewPandasJSONPricesProvider(dir string, fileName func(ticker entity.Ticker) string, decoder *Decoder) *pandasJSONPricesProvider {
+	if fileName == nil {
+		fileName = DefaultFileName
+	}
+	return &pandasJSONPricesProvider{dir: dir, fileName: fileName, decoder: decoder}
+}
+
+// DefaultFileName maps LON:ANP to LON_ANP.json, replacing separators and dots that could point outside of dir.
+func DefaultFileName(ticker entity.Ticker) string {
+	safe := strings.NewReplacer("/", "_", "\\", "_", "..", "_")
+	return safe.Replace(fmt.Sprintf("%s_%s.json", ticker.Market, ticker.Symbol))
+}
+
+// GetHistoricalPrices returns the prices in the ticker file falling in the interval.
+// An ErrNothingFound is returned when there is no file for the ticker or no price in the interval.
+func (provider *pandasJSONPricesProvider) GetHistoricalPrices(ctx context.Context, ticker entity.Ticker, interval entity.DateInterval) (entity.PriceHistory, error) {
+	if ctx.Err() != nil {
+		return entity.PriceHistory{}, ctx.Err()
+	}
+
+	fileName := filepath.Join(provider.dir, provider.fileName(ticker))
+	if relative, err := filepath.Rel(provider.dir, fileName); err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return entity.PriceHistory{}, entity.NewErrValidation(fmt.Sprintf("Invalid ticker %s", ticker))
+	}
+	file, err := os.Open(fileName)
+	if os.IsNotExist(err) {
+		return entity.PriceHistory{}, entity.NewErrNothingFound(ticker)
+	}
+	if err != nil {
+		return entity.PriceHistory{}, errors.Wrap(err, "unable to open prices file")
+	}
+	defer file.Close()
+
+	history, err := provider.decoder.DecodeHistory(file)
+	if err != nil {
+		return entity.PriceHistory{}, errors.Wrapf(entity.NewErrMalformedData("pandasjson", err.Error()), "unable to read prices file for ticker %s", ticker)
+	}
+
+	history = history.FilterByInterval(interval)
+	if len(history.Prices) == 0 {
+		return entity.PriceHistory{}, entity.NewErrNothingFound(ticker)
+	}
+
+	history.TickerInfo = entity.TickerInfo{Ticker: ticker}
+	return history, nil
+}
@@ -0,0 +1,76 @@
+package pandasjson
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"org.alex859/stockprices/domain/entity"
+)
+
+var anp = entity.Ticker{Market: "LON", Symbol: "ANP"}
+var sep4 = time.Date(2013, time.September, 4, 0, 0, 0, 0, time.UTC)
+var sep6 = time.Date(2013, time.September, 6, 0, 0, 0, 0, time.UTC)
+
+func testdataFile(name string) func(entity.Ticker) string {
+	return func(entity.Ticker) string {
+		return name
+	}
+}
+
+func Test_GetHistoricalPrices_WHEN_AllGood_THEN_ReturnPricesInInterval(t *testing.T) {
+	provider := NewPandasJSONPricesProvider("testdata", testdataFile("google_price_response.json"), NewDecoder(DefaultColumns))
+	interval, _ := entity.NewDateInterval(sep4, sep6)
+
+	result, err := provider.GetHistoricalPrices(context.Background(), anp, interval)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, entity.TickerInfo{Ticker: anp}, result.TickerInfo)
+		assert.Equal(t, entity.PriceList{
+			{Price: 166.0, Time: time.Date(2013, time.September, 4, 17, 30, 0, 0, time.UTC)},
+			{Price: 167.0, Time: time.Date(2013, time.September, 5, 17, 30, 0, 0, time.UTC)},
+			{Price: 172.5, Time: time.Date(2013, time.September, 6, 17, 30, 0, 0, time.UTC)},
+		}, result.Prices)
+	}
+}
+
+func Test_GetHistoricalPrices_WHEN_Mismatch_THEN_ReturnError(t *testing.T) {
+	provider := NewPandasJSONPricesProvider("testdata", testdataFile("google_price_response_mismatch.json"), NewDecoder(DefaultColumns))
+	interval, _ := entity.NewDateInterval(sep4, sep6)
+
+	_, err := provider.GetHistoricalPrices(context.Background(), anp, interval)
+
+	assert.Equal(t, entity.ParseFailure, entity.KindOf(err))
+}
+
+func Test_GetHistoricalPrices_WHEN_NoFile_THEN_ReturnNothingFound(t *testing.T) {
+	provider := NewPandasJSONPricesProvider("testdata", nil, NewDecoder(DefaultColumns))
+	interval, _ := entity.NewDateInterval(sep4, sep6)
+
+	_, err := provider.GetHistoricalPrices(context.Background(), anp, interval)
+
+	assert.IsType(t, entity.ErrNothingFound{}, err)
+}
+
+func Test_GetHistoricalPrices_WHEN_SymbolPointsOutside_THEN_DoNotReadIt(t *testing.T) {
+	provider := NewPandasJSONPricesProvider("testdata/nowhere", nil, NewDecoder(DefaultColumns))
+	interval, _ := entity.NewDateInterval(sep4, sep6)
+
+	_, err := provider.GetHistoricalPrices(context.Background(), entity.Ticker{Market: "x", Symbol: "../../google_price_response"}, interval)
+	assert.IsType(t, entity.ErrNothingFound{}, err)
+
+	provider = NewPandasJSONPricesProvider("testdata/nowhere", testdataFile("../google_price_response.json"), NewDecoder(DefaultColumns))
+	_, err = provider.GetHistoricalPrices(context.Background(), anp, interval)
+	assert.IsType(t, entity.ErrValidation{}, err)
+}
+
+func Test_GetHistoricalPrices_WHEN_NothingInInterval_THEN_ReturnNothingFound(t *testing.T) {
+	provider := NewPandasJSONPricesProvider("testdata", testdataFile("google_price_response.json"), NewDecoder(DefaultColumns))
+	from := time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+	interval, _ := entity.NewDateInterval(from, from.AddDate(0, 1, 0))
+
+	_, err := provider.GetHistoricalPrices(context.Background(), anp, interval)
+
+	assert.IsType(t, entity.ErrNothingFound{}, err)
+}
@@ -0,0 +1,174 @@
+package portfoliostore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	"org.alex859/stockprices/domain/entity"
+)
+
+// Portfolio IDs are used as file names, so they are kept to letters, digits, dashes and underscores.
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// How long saving a ledger waits for another save of it, how often it checks and how old a lock can get before
+// being taken as left behind.
+const (
+	lockWait          = 5 * time.Second
+	lockRetryInterval = 20 * time.Millisecond
+	lockStaleness     = time.Minute
+)
+
+// Keeps a JSON file per portfolio in a directory, along with another one for its transaction ledger.
+type fileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store keeping its files in the given directory, created when missing.
+func NewFileStore(dir string) *fileStore {
+	return &fileStore{dir: dir}
+}
+
+func (store *fileStore) GetPortfolio(ctx context.Context, id string) (entity.Portfolio, error) {
+	if !validID.MatchString(id) {
+		return entity.Portfolio{}, entity.NewErrValidation("Invalid portfolio id")
+	}
+	content, err := ioutil.ReadFile(store.fileName(id))
+	if os.IsNotExist(err) {
+		return entity.Portfolio{}, entity.NewErrPortfolioNotFound(id)
+	}
+	if err != nil {
+		return entity.Portfolio{}, errors.Wrapf(err, "unable to read portfolio %s", id)
+	}
+
+	var portfolio entity.Portfolio
+	if err = json.Unmarshal(content, &portfolio); err != nil {
+		return entity.Portfolio{}, errors.Wrapf(err, "unable to decode portfolio %s", id)
+	}
+	portfolio.ID = id
+	return portfolio, nil
+}
+
+// SavePortfolio writes the portfolio to a temporary file first, so that a failure never leaves a partial file behind.
+func (store *fileStore) SavePortfolio(ctx context.Context, portfolio entity.Portfolio) error {
+	if err := portfolio.Validate(); err != nil {
+		return err
+	}
+	if !validID.MatchString(portfolio.ID) {
+		return entity.NewErrValidation("Invalid portfolio id")
+	}
+	content, err := json.Marshal(portfolio)
+	if err != nil {
+		return errors.Wrapf(err, "unable to encode portfolio %s", portfolio.ID)
+	}
+	if err = os.MkdirAll(store.dir, 0755); err != nil {
+		return errors.Wrap(err, "unable to create store directory")
+	}
+
+	fileName := store.fileName(portfolio.ID)
+	if err = ioutil.WriteFile(fileName+".tmp", content, 0644); err != nil {
+		return errors.Wrapf(err, "unable to write portfolio %s", portfolio.ID)
+	}
+	return errors.Wrapf(os.Rename(fileName+".tmp", fileName), "unable to write portfolio %s", portfolio.ID)
+}
+
+func (store *fileStore) fileName(id string) string {
+	return filepath.Join(store.dir, id+".json")
+}
+
+func (store *fileStore) GetLedger(ctx context.Context, portfolioID string) (entity.Ledger, error) {
+	if !validID.MatchString(portfolioID) {
+		return entity.Ledger{}, entity.NewErrValidation("Invalid portfolio id")
+	}
+	content, err := ioutil.ReadFile(store.ledgerFileName(portfolioID))
+	if os.IsNotExist(err) {
+		return entity.Ledger{PortfolioID: portfolioID}, nil
+	}
+	if err != nil {
+		return entity.Ledger{}, errors.Wrapf(err, "unable to read the ledger of portfolio %s", portfolioID)
+	}
+
+	var ledger entity.Ledger
+	if err = json.Unmarshal(content, &ledger); err != nil {
+		return entity.Ledger{}, errors.Wrapf(err, "unable to decode the ledger of portfolio %s", portfolioID)
+	}
+	ledger.PortfolioID = portfolioID
+	return ledger, nil
+}
+
+// SaveLedger writes the ledger to a temporary file first, like SavePortfolio.
+// The ledger file is locked while its version is checked and it is written, so that concurrent saves, from any
+// process sharing the directory, are refused rather than lost.
+func (store *fileStore) SaveLedger(ctx context.Context, ledger entity.Ledger) error {
+	if !validID.MatchString(ledger.PortfolioID) {
+		return entity.NewErrValidation("Invalid portfolio id")
+	}
+	content, err := json.Marshal(ledger)
+	if err != nil {
+		return errors.Wrapf(err, "unable to encode the ledger of portfolio %s", ledger.PortfolioID)
+	}
+	if err = os.MkdirAll(store.dir, 0755); err != nil {
+		return errors.Wrap(err, "unable to create store directory")
+	}
+
+	unlock, err := store.lock(ctx, ledger.PortfolioID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	stored, err := store.GetLedger(ctx, ledger.PortfolioID)
+	if err != nil {
+		return err
+	}
+	if ledger.Version != stored.Version+1 {
+		return entity.NewErrConflict(fmt.Sprintf("The ledger of portfolio %s changed since it was read", ledger.PortfolioID))
+	}
+
+	fileName := store.ledgerFileName(ledger.PortfolioID)
+	if err = ioutil.WriteFile(fileName+".tmp", content, 0644); err != nil {
+		return errors.Wrapf(err, "unable to write the ledger of portfolio %s", ledger.PortfolioID)
+	}
+	return errors.Wrapf(os.Rename(fileName+".tmp", fileName), "unable to write the ledger of portfolio %s", ledger.PortfolioID)
+}
+
+// lock creates the lock file of the ledger, which a single caller can create at a time, waiting up to lockWait for
+// the current owner to remove it. Lock files older than lockStaleness are taken as left behind by a crash and removed.
+// It returns the function releasing the lock.
+func (store *fileStore) lock(ctx context.Context, portfolioID string) (func(), error) {
+	fileName := filepath.Join(store.dir, portfolioID+".ledger.lock")
+	deadline := time.Now().Add(lockWait)
+	for {
+		file, err := os.OpenFile(fileName, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(fileName) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, errors.Wrapf(err, "unable to lock the ledger of portfolio %s", portfolioID)
+		}
+		if info, err := os.Stat(fileName); err == nil && time.Since(info.ModTime()) > lockStaleness {
+			os.Remove(fileName)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, entity.NewErrConflict(fmt.Sprintf("The ledger of portfolio %s is being saved by another request", portfolioID))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// Portfolio IDs have no dots, so ledgers never clash with portfolios.
+func (store *fileStore) ledgerFileName(portfolioID string) string {
+	return filepath.Join(store.dir, portfolioID+".ledger.json")
+}
@@ -0,0 +1,69 @@
+package portfoliostore
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"org.alex859/stockprices/domain/entity"
+)
+
+func Test_FileStore_WHEN_Saved_THEN_Get(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "portfolios")
+	defer os.RemoveAll(dir)
+	store := NewFileStore(dir)
+	portfolio := entity.Portfolio{
+		ID:       "isa",
+		Holdings: []entity.Holding{{Ticker: entity.Ticker{Market: "LON", Symbol: "ANP"}, Quantity: 100, CostBasis: 150}},
+		Cash:     map[string]float64{"GBP": 50},
+	}
+
+	if assert.NoError(t, store.SavePortfolio(context.Background(), portfolio)) {
+		result, err := store.GetPortfolio(context.Background(), "isa")
+		assert.NoError(t, err)
+		assert.Equal(t, portfolio, result)
+	}
+}
+
+func Test_FileStore_WHEN_Unknown_THEN_ReturnNotFound(t *testing.T) {
+	store := NewFileStore("testdata/nowhere")
+
+	_, err := store.GetPortfolio(context.Background(), "isa")
+	assert.IsType(t, entity.ErrNothingFound{}, err)
+
+	_, err = store.GetPortfolio(context.Background(), "../isa")
+	assert.IsType(t, entity.ErrValidation{}, err)
+}
+
+func Test_FileStore_WHEN_LedgerSaved_THEN_GetLedger(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "portfolios")
+	defer os.RemoveAll(dir)
+	store := NewFileStore(dir)
+
+	empty, err := store.GetLedger(context.Background(), "isa")
+	assert.NoError(t, err)
+	assert.Equal(t, entity.Ledger{PortfolioID: "isa"}, empty)
+
+	at := time.Date(2018, time.May, 7, 10, 0, 0, 0, time.UTC)
+	ledger := entity.Ledger{PortfolioID: "isa", Currency: "GBP", Transactions: []entity.Transaction{{Time: at, Kind: entity.Deposit, Amount: 100}}, Version: 1}
+	if assert.NoError(t, store.SaveLedger(context.Background(), ledger)) {
+		result, err := store.GetLedger(context.Background(), "isa")
+		assert.NoError(t, err)
+		assert.Equal(t, ledger, result)
+	}
+}
+
+func Test_FileStore_WHEN_LedgerChangedMeanwhile_THEN_ReturnConflict(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "portfolios")
+	defer os.RemoveAll(dir)
+	store := NewFileStore(dir)
+	ledger := entity.Ledger{PortfolioID: "isa", Currency: "GBP", Version: 1}
+	assert.NoError(t, store.SaveLedger(context.Background(), ledger))
+
+	assert.IsType(t, entity.ErrConflict{}, store.SaveLedger(context.Background(), ledger), "saved from the same version")
+	ledger.Version = 2
+	assert.NoError(t, store.SaveLedger(context.Background(), ledger))
+}
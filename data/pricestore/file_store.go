@@ -0,0 +1,82 @@
+package pricestore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"org.alex859/stockprices/domain/entity"
+)
+
+// Record is what is known about the daily prices of a ticker.
+// Every day from KnownFrom to KnownTo has been asked for already, so prices missing in there do not exist.
+type Record struct {
+	TickerInfo entity.TickerInfo `json:"tickerInfo"`
+	Source     string            `json:"source,omitempty"`
+	KnownFrom  time.Time         `json:"knownFrom"`
+	KnownTo    time.Time         `json:"knownTo"`
+	UpdatedAt  time.Time         `json:"updatedAt"`
+	Prices     entity.PriceList  `json:"prices"`
+}
+
+// Store keeps records by ticker.
+type Store interface {
+	// Load returns the record of the ticker, found is false when there is none yet.
+	Load(ticker entity.Ticker) (record Record, found bool, err error)
+	// Save replaces the record of the ticker.
+	Save(ticker entity.Ticker, record Record) error
+}
+
+// Keeps a JSON file per ticker in a directory.
+type fileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store keeping its files in the given directory, created when missing.
+func NewFileStore(dir string) *fileStore {
+	return &fileStore{dir: dir}
+}
+
+func (store *fileStore) Load(ticker entity.Ticker) (Record, bool, error) {
+	content, err := ioutil.ReadFile(store.fileName(ticker))
+	if os.IsNotExist(err) {
+		return Record{}, false, nil
+	}
+	if err != nil {
+		return Record{}, false, errors.Wrapf(err, "unable to read stored prices for ticker %s", ticker)
+	}
+
+	var record Record
+	if err = json.Unmarshal(content, &record); err != nil {
+		return Record{}, false, errors.Wrapf(err, "unable to decode stored prices for ticker %s", ticker)
+	}
+	return record, true, nil
+}
+
+// Save writes the record to a temporary file first, so that a failure never leaves a partial file behind.
+func (store *fileStore) Save(ticker entity.Ticker, record Record) error {
+	content, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrapf(err, "unable to encode prices for ticker %s", ticker)
+	}
+	if err = os.MkdirAll(store.dir, 0755); err != nil {
+		return errors.Wrap(err, "unable to create store directory")
+	}
+
+	fileName := store.fileName(ticker)
+	if err = ioutil.WriteFile(fileName+".tmp", content, 0644); err != nil {
+		return errors.Wrapf(err, "unable to write prices for ticker %s", ticker)
+	}
+	return errors.Wrapf(os.Rename(fileName+".tmp", fileName), "unable to write prices for ticker %s", ticker)
+}
+
+// fileName keeps tickers from pointing outside of the directory.
+func (store *fileStore) fileName(ticker entity.Ticker) string {
+	safe := strings.NewReplacer("/", "_", "\\", "_", "..", "_")
+	return filepath.Join(store.dir, safe.Replace(fmt.Sprintf("%s_%s.json", ticker.Market, ticker.Symbol)))
+}
@@ -0,0 +1,197 @@
+package pricestore
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"org.alex859/stockprices/data/googlefinance"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase"
+)
+
+// Serves daily price histories from a store, only asking the provider for the days the store does not know yet.
+// Days are the exchange-local ones of the ticker market. Days before the current one are final and get stored, today's
+// prices are fetched every time and never stored.
+// Intraday requests, the ones Google Finance answers with prices every few minutes, go straight to the provider.
+// Only close prices are stored, histories served from the store come without bars.
+// When the provider fails, the stored prices are served as stale if they were updated up to maxStaleness ago.
+type incrementalPricesProvider struct {
+	provider     usecase.HistoricalPricesProvider
+	store        Store
+	maxStaleness time.Duration
+	locks        sync.Map
+	now          func() time.Time
+}
+
+// NewIncrementalPricesProvider creates a provider keeping the histories fetched from the given provider in the store.
+// Stored prices updated up to maxStaleness ago are served when the provider fails, zero meaning never.
+func NewIncrementalPricesProvider(provider usecase.HistoricalPricesProvider, store Store, maxStaleness time.Duration) *incrementalPricesProvider {
+	return &incrementalPricesProvider{provider: provider, store: store, maxStaleness: maxStaleness, now: time.Now}
+}
+
+func (provider *incrementalPricesProvider) GetHistoricalPrices(ctx context.Context, ticker entity.Ticker, interval entity.DateInterval) (entity.PriceHistory, error) {
+	// Prices before complete are final. Known days are dates, taken regardless of their location like the interval ones.
+	calendar := entity.CalendarOf(ticker.Market)
+	complete := calendar.Day(provider.now())
+	todayDate := time.Date(complete.Year(), complete.Month(), complete.Day(), 0, 0, 0, 0, time.UTC)
+	if start, _ := interval.Bounds(calendar); !start.Before(complete) || isIntraday(interval) {
+		return provider.provider.GetHistoricalPrices(ctx, ticker, interval)
+	}
+
+	lock := provider.lock(ticker)
+	lock.Lock()
+	defer lock.Unlock()
+
+	record, found, err := provider.store.Load(ticker)
+	if err != nil {
+		log.Printf("Ignoring stored prices for ticker: %s. Error: %+v", ticker, err)
+		found = false
+	}
+	if !found {
+		record = Record{KnownFrom: interval.From(), KnownTo: interval.From()}
+	}
+
+	var today entity.PriceList
+	changed := false
+	for _, missing := range missingIntervals(record, found, interval) {
+		history, err := provider.provider.GetHistoricalPrices(ctx, ticker, missing)
+		_, nothingFound := errors.Cause(err).(entity.ErrNothingFound)
+		if err != nil && !nothingFound {
+			return provider.stale(ticker, record, found, interval, err)
+		}
+
+		// Days without prices, e.g. weekends and holidays, become known as well, so that they are not asked for again.
+		if !nothingFound {
+			final, recent := splitAt(dailyCloses(history.Prices, calendar), complete)
+			record.TickerInfo, record.Source = history.TickerInfo, history.Source
+			record.Prices = merge(record.Prices, final)
+			today = recent
+		}
+		record.KnownFrom = earliest(record.KnownFrom, missing.From())
+		record.KnownTo = latest(record.KnownTo, earliest(missing.To(), todayDate))
+		record.UpdatedAt = provider.now()
+		changed = true
+	}
+
+	if changed {
+		if err = provider.store.Save(ticker, record); err != nil {
+			log.Printf("Unable to store prices for ticker: %s. Error: %+v", ticker, err)
+		}
+	}
+
+	prices := append(record.Prices.FilterIn(interval, calendar), today.FilterIn(interval, calendar)...)
+	if len(prices) == 0 {
+		return entity.PriceHistory{}, entity.NewErrNothingFound(ticker)
+	}
+	return entity.PriceHistory{TickerInfo: record.TickerInfo, Prices: prices, Source: record.Source}, nil
+}
+
+// stale serves the stored prices in place of the ones the provider failed to give, as long as they are recent enough.
+func (provider *incrementalPricesProvider) stale(ticker entity.Ticker, record Record, found bool, interval entity.DateInterval, err error) (entity.PriceHistory, error) {
+	now := provider.now()
+	if !found || now.Sub(record.UpdatedAt) > provider.maxStaleness {
+		return entity.PriceHistory{}, err
+	}
+
+	prices := record.Prices.FilterIn(interval, entity.CalendarOf(ticker.Market))
+	if len(prices) == 0 {
+		return entity.PriceHistory{}, err
+	}
+	log.Printf("Serving stale prices for ticker: %s, updated at %s. Error: %s", ticker, record.UpdatedAt, err)
+	return entity.PriceHistory{
+		TickerInfo: record.TickerInfo,
+		Prices:     prices,
+		Source:     record.Source,
+		Freshness:  entity.NewFreshness(record.UpdatedAt, now, true),
+	}, nil
+}
+
+// lock returns the lock guarding the record of the ticker, so that concurrent requests do not overwrite each other.
+func (provider *incrementalPricesProvider) lock(ticker entity.Ticker) *sync.Mutex {
+	lock, _ := provider.locks.LoadOrStore(ticker.String(), &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+// isIntraday tells if Google Finance would answer the interval with intraday prices, which are not stored.
+func isIntraday(interval entity.DateInterval) bool {
+	period := googlefinance.FromDateInterval(interval)
+	return period == googlefinance.OneDay || period == googlefinance.FiveDays
+}
+
+// missingIntervals works out what to ask the provider for: the days before and after the ones known by the record.
+func missingIntervals(record Record, found bool, interval entity.DateInterval) []entity.DateInterval {
+	if !found {
+		return []entity.DateInterval{interval}
+	}
+
+	var result []entity.DateInterval
+	if interval.From().Before(record.KnownFrom) {
+		head, _ := entity.NewDateInterval(interval.From(), record.KnownFrom)
+		result = append(result, head)
+	}
+	if interval.To().After(record.KnownTo) {
+		tail, _ := entity.NewDateInterval(record.KnownTo, interval.To())
+		result = append(result, tail)
+	}
+	return result
+}
+
+// dailyCloses keeps the last price of every exchange-local day, ordered by time.
+func dailyCloses(prices entity.PriceList, calendar entity.Calendar) entity.PriceList {
+	series := entity.NewPriceSeries(prices)
+
+	var result entity.PriceList
+	for i := 0; i < series.Len(); i++ {
+		if i+1 < series.Len() && calendar.SameDay(series.At(i).Time, series.At(i+1).Time) {
+			continue
+		}
+		result = append(result, series.At(i))
+	}
+	return result
+}
+
+// merge adds the prices to the list, replacing the ones at the same time, and keeps the list ordered by time.
+func merge(list entity.PriceList, prices entity.PriceList) entity.PriceList {
+	byTime := make(map[int64]entity.PricePoint, len(list)+len(prices))
+	for _, price := range list {
+		byTime[price.Time.UnixNano()] = price
+	}
+	for _, price := range prices {
+		byTime[price.Time.UnixNano()] = price
+	}
+
+	result := make(entity.PriceList, 0, len(byTime))
+	for _, price := range byTime {
+		result = append(result, price)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Time.Before(result[j].Time)
+	})
+	return result
+}
+
+// splitAt splits an ordered list in the prices before the given time and the other ones.
+func splitAt(prices entity.PriceList, t time.Time) (before entity.PriceList, after entity.PriceList) {
+	i := sort.Search(len(prices), func(i int) bool {
+		return !prices[i].Time.Before(t)
+	})
+	return prices[:i], prices[i:]
+}
+
+func earliest(t1 time.Time, t2 time.Time) time.Time {
+	if t2.Before(t1) {
+		return t2
+	}
+	return t1
+}
+
+func latest(t1 time.Time, t2 time.Time) time.Time {
+	if t2.After(t1) {
+		return t2
+	}
+	return t1
+}
@@ -0,0 +1,183 @@
+package pricestore
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase/mocks"
+)
+
+var anp = entity.Ticker{Market: "LON", Symbol: "ANP"}
+var anpInfo = entity.TickerInfo{Name: "Anpario", Ticker: anp, Currency: "GBX"}
+
+func day(d int) time.Time {
+	return time.Date(2018, time.May, d, 0, 0, 0, 0, time.UTC)
+}
+
+func closeOn(d int, price float64) entity.PricePoint {
+	return entity.PricePoint{Price: price, Time: time.Date(2018, time.May, d, 16, 30, 0, 0, time.UTC)}
+}
+
+func interval(from int, to int) entity.DateInterval {
+	result, _ := entity.NewDateInterval(day(from), day(to))
+	return result
+}
+
+func tempStore(t *testing.T) (*fileStore, func()) {
+	dir, err := ioutil.TempDir("", "pricestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewFileStore(dir), func() { os.RemoveAll(dir) }
+}
+
+func newTestProvider(upstream *mocks.HistoricalPricesProvider, store Store, today int) *incrementalPricesProvider {
+	provider := NewIncrementalPricesProvider(upstream, store, 0)
+	provider.now = func() time.Time { return day(today).Add(10 * time.Hour) }
+	return provider
+}
+
+func Test_fileStore_WHEN_Saved_THEN_LoadIt(t *testing.T) {
+	store, cleanUp := tempStore(t)
+	defer cleanUp()
+	record := Record{TickerInfo: anpInfo, KnownFrom: day(1), KnownTo: day(4), Prices: entity.PriceList{closeOn(2, 10), closeOn(3, 11)}}
+
+	_, found, err := store.Load(anp)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	if assert.NoError(t, store.Save(anp, record)) {
+		loaded, found, err := store.Load(anp)
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, record, loaded)
+	}
+}
+
+func Test_GetHistoricalPrices_WHEN_NothingStored_THEN_FetchAllAndStoreCompleteDays(t *testing.T) {
+	store, cleanUp := tempStore(t)
+	defer cleanUp()
+	upstream := &mocks.HistoricalPricesProvider{}
+	upstream.On("GetHistoricalPrices", mock.Anything, anp, interval(1, 10)).Return(entity.PriceHistory{
+		TickerInfo: anpInfo,
+		Prices:     entity.PriceList{closeOn(2, 10), closeOn(3, 11), closeOn(10, 12)},
+	}, nil)
+
+	result, err := newTestProvider(upstream, store, 10).GetHistoricalPrices(context.Background(), anp, interval(1, 10))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, entity.PriceList{closeOn(2, 10), closeOn(3, 11), closeOn(10, 12)}, result.Prices)
+	}
+	record, _, _ := store.Load(anp)
+	assert.Equal(t, entity.PriceList{closeOn(2, 10), closeOn(3, 11)}, record.Prices, "today should not be stored")
+	assert.Equal(t, day(1), record.KnownFrom)
+	assert.Equal(t, day(10), record.KnownTo)
+}
+
+func Test_GetHistoricalPrices_WHEN_Stored_THEN_FetchOnlyMissingDays(t *testing.T) {
+	store, cleanUp := tempStore(t)
+	defer cleanUp()
+	store.Save(anp, Record{TickerInfo: anpInfo, KnownFrom: day(3), KnownTo: day(8), Prices: entity.PriceList{closeOn(3, 11), closeOn(7, 12)}})
+	upstream := &mocks.HistoricalPricesProvider{}
+	upstream.On("GetHistoricalPrices", mock.Anything, anp, interval(1, 3)).Return(entity.PriceHistory{TickerInfo: anpInfo, Prices: entity.PriceList{closeOn(2, 10), closeOn(3, 11)}}, nil)
+	upstream.On("GetHistoricalPrices", mock.Anything, anp, interval(8, 12)).Return(entity.PriceHistory{TickerInfo: anpInfo, Prices: entity.PriceList{closeOn(9, 13), closeOn(12, 14)}}, nil)
+
+	result, err := newTestProvider(upstream, store, 12).GetHistoricalPrices(context.Background(), anp, interval(1, 12))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, entity.PriceList{closeOn(2, 10), closeOn(3, 11), closeOn(7, 12), closeOn(9, 13), closeOn(12, 14)}, result.Prices)
+		assert.Equal(t, anpInfo, result.TickerInfo)
+	}
+	upstream.AssertNumberOfCalls(t, "GetHistoricalPrices", 2)
+	record, _, _ := store.Load(anp)
+	assert.Equal(t, day(1), record.KnownFrom)
+	assert.Equal(t, day(12), record.KnownTo)
+}
+
+func Test_GetHistoricalPrices_WHEN_AllKnown_THEN_DoNotFetch(t *testing.T) {
+	store, cleanUp := tempStore(t)
+	defer cleanUp()
+	store.Save(anp, Record{TickerInfo: anpInfo, KnownFrom: day(1), KnownTo: day(8), Prices: entity.PriceList{closeOn(3, 11), closeOn(7, 12)}})
+	upstream := &mocks.HistoricalPricesProvider{}
+
+	result, err := newTestProvider(upstream, store, 12).GetHistoricalPrices(context.Background(), anp, interval(2, 5))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, entity.PriceList{closeOn(3, 11)}, result.Prices)
+	}
+	upstream.AssertNotCalled(t, "GetHistoricalPrices", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_GetHistoricalPrices_WHEN_NothingInMissingDays_THEN_KnowThem(t *testing.T) {
+	store, cleanUp := tempStore(t)
+	defer cleanUp()
+	store.Save(anp, Record{TickerInfo: anpInfo, KnownFrom: day(7), KnownTo: day(8), Prices: entity.PriceList{closeOn(7, 12)}})
+	upstream := &mocks.HistoricalPricesProvider{}
+	// 5 and 6 May 2018 are a weekend.
+	upstream.On("GetHistoricalPrices", mock.Anything, anp, interval(5, 7)).Return(entity.PriceHistory{}, entity.NewErrNothingFound(anp))
+	provider := newTestProvider(upstream, store, 12)
+
+	for i := 0; i < 2; i++ {
+		result, err := provider.GetHistoricalPrices(context.Background(), anp, interval(5, 8))
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, entity.PriceList{closeOn(7, 12)}, result.Prices)
+		}
+	}
+	upstream.AssertNumberOfCalls(t, "GetHistoricalPrices", 1)
+	record, _, _ := store.Load(anp)
+	assert.Equal(t, day(5), record.KnownFrom)
+}
+
+func Test_GetHistoricalPrices_WHEN_UpstreamFails_THEN_ReturnErrorAndKeepStore(t *testing.T) {
+	store, cleanUp := tempStore(t)
+	defer cleanUp()
+	stored := Record{TickerInfo: anpInfo, KnownFrom: day(1), KnownTo: day(5), Prices: entity.PriceList{closeOn(3, 11)}}
+	store.Save(anp, stored)
+	upstream := &mocks.HistoricalPricesProvider{}
+	upstream.On("GetHistoricalPrices", mock.Anything, anp, interval(5, 9)).Return(entity.PriceHistory{}, errors.New("can't talk to server"))
+
+	_, err := newTestProvider(upstream, store, 12).GetHistoricalPrices(context.Background(), anp, interval(1, 9))
+
+	assert.Error(t, err)
+	record, _, _ := store.Load(anp)
+	assert.Equal(t, stored, record)
+}
+
+func Test_GetHistoricalPrices_WHEN_UpstreamFailsAndStoredRecently_THEN_ServeStoredAsStale(t *testing.T) {
+	store, cleanUp := tempStore(t)
+	defer cleanUp()
+	store.Save(anp, Record{TickerInfo: anpInfo, KnownFrom: day(1), KnownTo: day(5), UpdatedAt: day(11), Prices: entity.PriceList{closeOn(3, 11)}})
+	upstream := &mocks.HistoricalPricesProvider{}
+	upstream.On("GetHistoricalPrices", mock.Anything, anp, interval(5, 9)).Return(entity.PriceHistory{}, errors.New("can't talk to server"))
+	provider := newTestProvider(upstream, store, 12)
+	provider.maxStaleness = 48 * time.Hour
+
+	result, err := provider.GetHistoricalPrices(context.Background(), anp, interval(1, 9))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, entity.PriceList{closeOn(3, 11)}, result.Prices)
+		assert.Equal(t, &entity.Freshness{FetchedAt: day(11), Age: 34 * time.Hour, Stale: true}, result.Freshness)
+	}
+}
+
+func Test_dailyCloses_WHEN_IntradayPrices_THEN_KeepLastOfEachDay(t *testing.T) {
+	prices := entity.PriceList{
+		{Price: 3, Time: time.Date(2018, time.May, 2, 15, 0, 0, 0, time.UTC)},
+		{Price: 1, Time: time.Date(2018, time.May, 1, 9, 0, 0, 0, time.UTC)},
+		{Price: 2, Time: time.Date(2018, time.May, 1, 16, 0, 0, 0, time.UTC)},
+	}
+
+	assert.Equal(t, entity.PriceList{prices[2], prices[0]}, dailyCloses(prices, entity.UTCCalendar))
+
+	// 2 May at 01:00 UTC is still 1 May in New York.
+	late := append(prices, entity.PricePoint{Price: 4, Time: time.Date(2018, time.May, 2, 1, 0, 0, 0, time.UTC)})
+	assert.Equal(t, entity.PriceList{late[3], prices[0]}, dailyCloses(late, entity.CalendarOf("NASDAQ")))
+}
@@ -0,0 +1,82 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+)
+
+// Matrix is a square matrix of measures between lists of values, nil where a measure cannot be computed,
+// e.g. the correlation with values that never change.
+type Matrix [][]*float64
+
+// Pearson returns the Pearson correlation of two lists of values of the same length.
+func Pearson(xs []float64, ys []float64) (result float64, ok bool) {
+	if len(xs) < 2 || len(xs) != len(ys) {
+		return 0, false
+	}
+	varianceX, varianceY := covariance(xs, xs), covariance(ys, ys)
+	if varianceX == 0 || varianceY == 0 {
+		return 0, false
+	}
+	return covariance(xs, ys) / math.Sqrt(varianceX*varianceY), true
+}
+
+// Spearman returns the Spearman rank correlation of two lists of values of the same length, ties sharing their
+// average rank.
+func Spearman(xs []float64, ys []float64) (result float64, ok bool) {
+	if len(xs) != len(ys) {
+		return 0, false
+	}
+	return Pearson(ranks(xs), ranks(ys))
+}
+
+// Covariance returns the sample covariance of two lists of values of the same length.
+func Covariance(xs []float64, ys []float64) (result float64, ok bool) {
+	if len(xs) < 2 || len(xs) != len(ys) {
+		return 0, false
+	}
+	return covariance(xs, ys), true
+}
+
+// NewMatrix computes the measure between every pair of lists of values.
+func NewMatrix(values [][]float64, measure func(xs []float64, ys []float64) (float64, bool)) Matrix {
+	result := make(Matrix, len(values))
+	for i := range values {
+		result[i] = make([]*float64, len(values))
+		for j := range values {
+			if j < i {
+				result[i][j] = result[j][i]
+				continue
+			}
+			if value, ok := measure(values[i], values[j]); ok {
+				result[i][j] = &value
+			}
+		}
+	}
+	return result
+}
+
+// ranks returns the rank of every value, from 1 for the lowest one.
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return values[order[i]] < values[order[j]]
+	})
+
+	result := make([]float64, len(values))
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && values[order[end]] == values[order[start]] {
+			end++
+		}
+		rank := float64(start+end+1) / 2
+		for _, i := range order[start:end] {
+			result[i] = rank
+		}
+		start = end
+	}
+	return result
+}
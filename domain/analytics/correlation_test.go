@@ -0,0 +1,43 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Pearson(t *testing.T) {
+	correlation, ok := Pearson([]float64{1, 2, 3, 4}, []float64{2, 4, 6, 8})
+	assert.True(t, ok)
+	assert.InDelta(t, 1, correlation, 1e-9)
+
+	correlation, _ = Pearson([]float64{1, 2, 3, 4}, []float64{4, 3, 2, 1})
+	assert.InDelta(t, -1, correlation, 1e-9)
+
+	_, ok = Pearson([]float64{1, 2, 3}, []float64{5, 5, 5})
+	assert.False(t, ok, "no variance")
+	_, ok = Pearson([]float64{1}, []float64{1})
+	assert.False(t, ok)
+}
+
+func Test_Spearman_THEN_OnlyOrderCounts(t *testing.T) {
+	correlation, ok := Spearman([]float64{1, 2, 3, 4}, []float64{1, 10, 100, 1000})
+	assert.True(t, ok)
+	assert.InDelta(t, 1, correlation, 1e-9)
+
+	// Ranks of the second list are 1.5, 1.5, 3 and 4.
+	correlation, _ = Spearman([]float64{1, 2, 3, 4}, []float64{7, 7, 8, 9})
+	assert.InDelta(t, 0.9486832980505138, correlation, 1e-9)
+}
+
+func Test_NewMatrix(t *testing.T) {
+	values := [][]float64{{1, 2, 3}, {2, 4, 7}, {5, 5, 5}}
+
+	matrix := NewMatrix(values, Covariance)
+
+	assert.InDelta(t, 1, *matrix[0][0], 1e-9)
+	assert.InDelta(t, 2.5, *matrix[0][1], 1e-9)
+	assert.Equal(t, matrix[0][1], matrix[1][0])
+	assert.InDelta(t, 0, *matrix[2][2], 1e-9)
+	assert.Nil(t, NewMatrix(values, Pearson)[0][2])
+}
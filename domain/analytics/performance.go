@@ -0,0 +1,95 @@
+package analytics
+
+import (
+	"math"
+	"time"
+
+	"org.alex859/stockprices/domain/entity"
+)
+
+// CashFlow is money paid, when negative, or received, when positive, by an investor at a given time.
+type CashFlow struct {
+	Time   time.Time `json:"time"`
+	Amount float64   `json:"amount"`
+}
+
+// xirrTolerance is how close to zero the net present value of the cash flows has to get.
+const xirrTolerance = 1e-9
+
+// TimeWeightedReturn returns the return of the daily values over the whole period, chaining the daily returns so that
+// deposits and withdrawals do not count. Flows are taken at the start of their day.
+// Days following a zero value, e.g. before the first deposit, are left out.
+func TimeWeightedReturn(values []entity.DailyValue) (result float64, ok bool) {
+	growth := 1.0
+	for i := 1; i < len(values); i++ {
+		start := values[i-1].Value + values[i].Flow
+		if start == 0 {
+			continue
+		}
+		growth *= values[i].Value / start
+		ok = true
+	}
+	return growth - 1, ok
+}
+
+// InvestorCashFlows returns the cash flows of an investor buying the portfolio at the first value, depositing and
+// withdrawing along the way and selling it at the last value.
+func InvestorCashFlows(values []entity.DailyValue) []CashFlow {
+	if len(values) == 0 {
+		return nil
+	}
+	result := []CashFlow{{Time: values[0].Time, Amount: -values[0].Value}}
+	for _, value := range values[1:] {
+		if value.Flow != 0 {
+			result = append(result, CashFlow{Time: value.Time, Amount: -value.Flow})
+		}
+	}
+	last := values[len(values)-1]
+	return append(result, CashFlow{Time: last.Time, Amount: last.Value})
+}
+
+// XIRR returns the yearly rate making the net present value of the cash flows zero, i.e. the money weighted return.
+// ok is false when there is no such rate, e.g. when all the flows have the same sign.
+func XIRR(flows []CashFlow) (result float64, ok bool) {
+	if len(flows) < 2 {
+		return 0, false
+	}
+	start := flows[0].Time
+	for _, flow := range flows {
+		if flow.Time.Before(start) {
+			start = flow.Time
+		}
+	}
+	npv := func(rate float64) float64 {
+		sum := 0.0
+		for _, flow := range flows {
+			years := flow.Time.Sub(start).Hours() / 24 / daysPerYear
+			sum += flow.Amount / math.Pow(1+rate, years)
+		}
+		return sum
+	}
+
+	// Bisection, as the net present value of usual flows only decreases with the rate, but Newton's method can jump
+	// out of the rates above -100%.
+	// Rates annualizing returns over a few days can get huge, hence the high bound.
+	low, high := -0.9999, 1.0
+	for npv(high) > 0 && high < 1e15 {
+		high *= 2
+	}
+	if math.Signbit(npv(low)) == math.Signbit(npv(high)) {
+		return 0, false
+	}
+	for i := 0; i < 200; i++ {
+		middle := (low + high) / 2
+		value := npv(middle)
+		if math.Abs(value) < xirrTolerance || high-low < xirrTolerance {
+			return middle, true
+		}
+		if math.Signbit(value) == math.Signbit(npv(low)) {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+	return (low + high) / 2, true
+}
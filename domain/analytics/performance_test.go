@@ -0,0 +1,61 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"org.alex859/stockprices/domain/entity"
+)
+
+func Test_TimeWeightedReturn_THEN_IgnoreFlows(t *testing.T) {
+	values := []entity.DailyValue{
+		{Time: day(7), Value: 1050},
+		{Time: day(8), Value: 1600, Flow: 500},
+		{Time: day(9), Value: 1670},
+	}
+
+	result, ok := TimeWeightedReturn(values)
+
+	assert.True(t, ok)
+	assert.InDelta(t, 1670.0/1550-1, result, 1e-12)
+	_, ok = TimeWeightedReturn([]entity.DailyValue{{Time: day(7)}, {Time: day(8)}})
+	assert.False(t, ok)
+}
+
+func Test_InvestorCashFlows(t *testing.T) {
+	values := []entity.DailyValue{
+		{Time: day(7), Value: 1050},
+		{Time: day(8), Value: 1600, Flow: 500},
+		{Time: day(9), Value: 1670},
+	}
+
+	assert.Equal(t, []CashFlow{
+		{Time: day(7), Amount: -1050},
+		{Time: day(8), Amount: -500},
+		{Time: day(9), Amount: 1670},
+	}, InvestorCashFlows(values))
+}
+
+func Test_XIRR(t *testing.T) {
+	year := time.Duration(daysPerYear * 24 * float64(time.Hour))
+	result, ok := XIRR([]CashFlow{{Time: day(1), Amount: -100}, {Time: day(1).Add(year), Amount: 110}})
+	assert.True(t, ok)
+	assert.InDelta(t, 0.1, result, 1e-6)
+
+	// 100 invested for two years and 100 for one, ending with 231: 10% a year.
+	result, ok = XIRR([]CashFlow{
+		{Time: day(1), Amount: -100},
+		{Time: day(1).Add(year), Amount: -100},
+		{Time: day(1).Add(2 * year), Amount: 231},
+	})
+	assert.True(t, ok)
+	assert.InDelta(t, 0.1, result, 1e-6)
+
+	result, ok = XIRR([]CashFlow{{Time: day(1), Amount: -100}, {Time: day(1).Add(year), Amount: 50}})
+	assert.True(t, ok)
+	assert.InDelta(t, -0.5, result, 1e-6)
+
+	_, ok = XIRR([]CashFlow{{Time: day(1), Amount: 100}, {Time: day(2), Amount: 50}})
+	assert.False(t, ok)
+}
@@ -0,0 +1,215 @@
+// Package analytics computes returns and risk measures over price histories.
+// Measures that cannot be computed, e.g. the volatility of a single return, come with ok set to false.
+package analytics
+
+import (
+	"math"
+	"time"
+
+	"org.alex859/stockprices/domain/entity"
+)
+
+// TradingDaysPerYear is used to annualize daily measures.
+const TradingDaysPerYear = 252
+
+// daysPerYear is used to annualize returns over calendar time.
+const daysPerYear = 365.25
+
+type (
+	// Return is the log return from the previous close to the close of the day starting at Time.
+	Return struct {
+		Time  time.Time `json:"time"`
+		Value float64   `json:"value"`
+	}
+
+	// Drawdown is a fall of the prices from the peak at Start to the trough at End, Depth being the fraction lost.
+	Drawdown struct {
+		Depth float64   `json:"depth"`
+		Start time.Time `json:"start"`
+		End   time.Time `json:"end"`
+	}
+)
+
+var daily = entity.Resolution{Count: 1, Unit: entity.Day}
+
+// DailyCloses returns the last price of every exchange-local day of the history, at the start of the day.
+// Histories without prices use the closes of their bars.
+func DailyCloses(history entity.PriceHistory) entity.PriceList {
+	prices := history.Prices
+	if len(prices) == 0 {
+		prices = history.Bars.Closes()
+	}
+	return prices.Resample(daily, history.Calendar().Location, entity.LastPrice)
+}
+
+// LogReturns returns the log returns between consecutive closes, which are expected in chronological order.
+// Non positive closes have no return and are skipped.
+func LogReturns(closes entity.PriceList) []Return {
+	result := []Return{}
+	var previous *entity.PricePoint
+	for i := range closes {
+		if closes[i].Price <= 0 {
+			continue
+		}
+		if previous != nil {
+			result = append(result, Return{Time: closes[i].Time, Value: math.Log(closes[i].Price / previous.Price)})
+		}
+		previous = &closes[i]
+	}
+	return result
+}
+
+// PeriodReturn returns the return from the first close to the last one, e.g. 0.1 for 10%.
+func PeriodReturn(closes entity.PriceList) (result float64, ok bool) {
+	if len(closes) < 2 || closes[0].Price <= 0 {
+		return 0, false
+	}
+	return closes[len(closes)-1].Price/closes[0].Price - 1, true
+}
+
+// AnnualizedReturn returns the compound yearly return that gives the period return over the time between the first
+// close and the last one. ok is false when it overflows, e.g. for a large return over a few seconds.
+func AnnualizedReturn(closes entity.PriceList) (result float64, ok bool) {
+	periodReturn, ok := PeriodReturn(closes)
+	if !ok {
+		return 0, false
+	}
+	years := closes[len(closes)-1].Time.Sub(closes[0].Time).Hours() / 24 / daysPerYear
+	if years <= 0 {
+		return 0, false
+	}
+	result = math.Pow(1+periodReturn, 1/years) - 1
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		return 0, false
+	}
+	return result, true
+}
+
+// Volatility returns the annualized standard deviation of the daily returns.
+func Volatility(returns []Return) (result float64, ok bool) {
+	deviation, ok := standardDeviation(returns)
+	return deviation * math.Sqrt(TradingDaysPerYear), ok
+}
+
+// MaxDrawdown returns the largest fall of the closes, which are expected in chronological order, from a peak to a
+// following trough. Closes that never fall have a drawdown of zero depth at the first close.
+func MaxDrawdown(closes entity.PriceList) Drawdown {
+	var result Drawdown
+	if len(closes) == 0 {
+		return result
+	}
+	result.Start, result.End = closes[0].Time, closes[0].Time
+	peak := closes[0]
+	for _, price := range closes {
+		if price.Price > peak.Price {
+			peak = price
+		}
+		if peak.Price <= 0 {
+			continue
+		}
+		if depth := 1 - price.Price/peak.Price; depth > result.Depth {
+			result = Drawdown{Depth: depth, Start: peak.Time, End: price.Time}
+		}
+	}
+	return result
+}
+
+// Sharpe returns the annualized Sharpe ratio of the daily returns, riskFree being the yearly risk free rate, e.g. 0.02.
+func Sharpe(returns []Return, riskFree float64) (result float64, ok bool) {
+	deviation, ok := standardDeviation(returns)
+	if !ok || deviation == 0 {
+		return 0, false
+	}
+	return (mean(returns) - dailyRate(riskFree)) / deviation * math.Sqrt(TradingDaysPerYear), true
+}
+
+// Sortino returns the annualized Sortino ratio of the daily returns, only penalising returns below the risk free rate.
+func Sortino(returns []Return, riskFree float64) (result float64, ok bool) {
+	if len(returns) < 2 {
+		return 0, false
+	}
+	target := dailyRate(riskFree)
+	downside := 0.0
+	for _, r := range returns {
+		if r.Value < target {
+			downside += (r.Value - target) * (r.Value - target)
+		}
+	}
+	if downside == 0 {
+		return 0, false
+	}
+	deviation := math.Sqrt(downside / float64(len(returns)))
+	return (mean(returns) - target) / deviation * math.Sqrt(TradingDaysPerYear), true
+}
+
+// Beta returns the beta of the returns against the ones of a benchmark, over the days both have a return in.
+// Days are compared by date, each return in its own location.
+func Beta(returns []Return, benchmark []Return) (result float64, ok bool) {
+	xs, ys := Common(benchmark, returns)
+	if len(xs) < 2 {
+		return 0, false
+	}
+	variance := covariance(xs, xs)
+	if variance == 0 {
+		return 0, false
+	}
+	return covariance(xs, ys) / variance, true
+}
+
+// Common returns the values of both returns on the dates they share, in the order of the first ones.
+// Dates are compared each in the location of their return, so the closes of exchanges in different time zones match.
+func Common(a []Return, b []Return) (as []float64, bs []float64) {
+	byDate := make(map[string]float64, len(b))
+	for _, r := range b {
+		byDate[r.Time.Format("2006-01-02")] = r.Value
+	}
+	for _, r := range a {
+		if value, ok := byDate[r.Time.Format("2006-01-02")]; ok {
+			as = append(as, r.Value)
+			bs = append(bs, value)
+		}
+	}
+	return as, bs
+}
+
+// dailyRate returns the daily log return of the yearly rate.
+func dailyRate(yearly float64) float64 {
+	return math.Log(1+yearly) / TradingDaysPerYear
+}
+
+func mean(returns []Return) float64 {
+	sum := 0.0
+	for _, r := range returns {
+		sum += r.Value
+	}
+	return sum / float64(len(returns))
+}
+
+// standardDeviation returns the sample standard deviation of the returns.
+func standardDeviation(returns []Return) (result float64, ok bool) {
+	if len(returns) < 2 {
+		return 0, false
+	}
+	values := make([]float64, len(returns))
+	for i, r := range returns {
+		values[i] = r.Value
+	}
+	return math.Sqrt(covariance(values, values)), true
+}
+
+// covariance returns the sample covariance of two lists of values of the same length, at least two.
+func covariance(xs []float64, ys []float64) float64 {
+	meanX, meanY := 0.0, 0.0
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+
+	sum := 0.0
+	for i := range xs {
+		sum += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	return sum / float64(len(xs)-1)
+}
@@ -0,0 +1,121 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"org.alex859/stockprices/domain/entity"
+)
+
+func day(d int) time.Time {
+	return time.Date(2018, time.May, d, 0, 0, 0, 0, time.UTC)
+}
+
+func closes(values ...float64) entity.PriceList {
+	result := make(entity.PriceList, len(values))
+	for i, value := range values {
+		result[i] = entity.PricePoint{Price: value, Time: day(i + 1)}
+	}
+	return result
+}
+
+func returns(values ...float64) []Return {
+	result := make([]Return, len(values))
+	for i, value := range values {
+		result[i] = Return{Time: day(i + 2), Value: value}
+	}
+	return result
+}
+
+func Test_DailyCloses_THEN_TakeLastPriceOfExchangeDays(t *testing.T) {
+	newYork := entity.CalendarOf("NYSE").Location
+	history := entity.PriceHistory{
+		TickerInfo: entity.TickerInfo{Ticker: entity.Ticker{Market: "NYSE", Symbol: "SQ"}},
+		Prices: entity.PriceList{
+			{Price: 2, Time: time.Date(2018, time.May, 8, 1, 0, 0, 0, time.UTC)},
+			{Price: 1, Time: time.Date(2018, time.May, 7, 15, 0, 0, 0, time.UTC)},
+			{Price: 3, Time: time.Date(2018, time.May, 8, 15, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	assert.Equal(t, entity.PriceList{
+		{Price: 2, Time: time.Date(2018, time.May, 7, 0, 0, 0, 0, newYork)},
+		{Price: 3, Time: time.Date(2018, time.May, 8, 0, 0, 0, 0, newYork)},
+	}, DailyCloses(history))
+}
+
+func Test_LogReturns(t *testing.T) {
+	result := LogReturns(closes(100, 110, 0, 99))
+
+	if assert.Len(t, result, 2) {
+		assert.Equal(t, Return{Time: day(2), Value: math.Log(1.1)}, result[0])
+		assert.Equal(t, Return{Time: day(4), Value: math.Log(0.9)}, result[1], "the zero close is skipped")
+	}
+}
+
+func Test_PeriodAndAnnualizedReturn(t *testing.T) {
+	yearly := entity.PriceList{{Price: 100, Time: day(1)}, {Price: 121, Time: day(1).Add(2 * daysPerYear * 24 * time.Hour)}}
+
+	periodReturn, ok := PeriodReturn(yearly)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.21, periodReturn, 1e-9)
+	annualized, ok := AnnualizedReturn(yearly)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.1, annualized, 1e-9)
+
+	_, ok = PeriodReturn(closes(100))
+	assert.False(t, ok)
+	_, ok = AnnualizedReturn(entity.PriceList{{Price: 1, Time: day(1)}, {Price: 2, Time: day(1)}})
+	assert.False(t, ok)
+	_, ok = AnnualizedReturn(entity.PriceList{{Price: 1, Time: day(1)}, {Price: 1000, Time: day(1).Add(time.Second)}})
+	assert.False(t, ok, "the annualized return overflows")
+}
+
+func Test_Volatility(t *testing.T) {
+	volatility, ok := Volatility(returns(0.01, -0.01, 0.01, -0.01))
+
+	assert.True(t, ok)
+	assert.InDelta(t, math.Sqrt(0.0004/3*TradingDaysPerYear), volatility, 1e-12)
+	_, ok = Volatility(returns(0.01))
+	assert.False(t, ok)
+}
+
+func Test_MaxDrawdown(t *testing.T) {
+	assert.Equal(t, Drawdown{Depth: 0.5, Start: day(4), End: day(6)}, MaxDrawdown(closes(100, 80, 100, 120, 90, 60, 110, 130, 100)))
+	assert.Equal(t, Drawdown{Start: day(1), End: day(1)}, MaxDrawdown(closes(1, 2, 3)))
+	assert.Equal(t, Drawdown{}, MaxDrawdown(nil))
+}
+
+func Test_SharpeAndSortino(t *testing.T) {
+	daily := returns(0.02, -0.01, 0.02, -0.01)
+
+	sharpe, ok := Sharpe(daily, 0)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.005/math.Sqrt(0.0003)*math.Sqrt(TradingDaysPerYear), sharpe, 1e-9)
+
+	sortino, ok := Sortino(daily, 0)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.005/math.Sqrt(0.0002/4)*math.Sqrt(TradingDaysPerYear), sortino, 1e-9)
+
+	withRiskFree, _ := Sharpe(daily, 0.05)
+	assert.True(t, withRiskFree < sharpe)
+
+	_, ok = Sharpe(returns(0.01, 0.01), 0)
+	assert.False(t, ok, "no volatility")
+	_, ok = Sortino(returns(0.01, 0.02), 0)
+	assert.False(t, ok, "no downside")
+}
+
+func Test_Beta(t *testing.T) {
+	benchmark := returns(0.01, -0.02, 0.03, 0.01)
+	doubled := []Return{{Time: day(3), Value: -0.04}, {Time: day(4), Value: 0.06}, {Time: day(5), Value: 0.02}, {Time: day(9), Value: 1}}
+
+	beta, ok := Beta(doubled, benchmark)
+
+	assert.True(t, ok)
+	assert.InDelta(t, 2, beta, 1e-9, "only the common days count")
+	_, ok = Beta(returns(0.01), benchmark)
+	assert.False(t, ok)
+}
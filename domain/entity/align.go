@@ -0,0 +1,154 @@
+package entity
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// FillKind tells how to fill the times of the axis a ticker has no price at.
+	FillKind int
+
+	// FillPolicy tells how to fill the times of the axis a ticker has no price at.
+	// MaxAge bounds how old a forward filled price can be, zero meaning no bound.
+	FillPolicy struct {
+		Kind   FillKind
+		MaxAge time.Duration
+	}
+
+	// AxisKind tells which times make the common axis.
+	AxisKind int
+
+	// AlignOptions tells how to align price histories.
+	AlignOptions struct {
+		// Resolution, when not nil, resamples the prices first, so that prices of the same day or hour share a time.
+		Resolution *Resolution
+		// Location is used to resample the prices. Defaults to UTC, where the closes of most exchanges fall on their own day.
+		Location *time.Location
+		Axis     AxisKind
+		Fill     FillPolicy
+	}
+
+	// AlignedPrices are prices of several tickers on a common time axis, nil where a ticker has no price.
+	AlignedPrices struct {
+		Times  []time.Time           `json:"times"`
+		Prices map[string][]*float64 `json:"prices"`
+	}
+)
+
+const (
+	// NoFill leaves the times without a price empty.
+	NoFill FillKind = iota
+	// ForwardFill takes the last price before the time, if not older than MaxAge.
+	ForwardFill
+	// LinearFill interpolates between the prices before and after the time, times outside of the history are left empty.
+	LinearFill
+)
+
+const (
+	// UnionAxis has the times of all the histories.
+	UnionAxis AxisKind = iota
+	// IntersectionAxis has the times all the histories have a price at.
+	IntersectionAxis
+)
+
+var fillKinds = map[string]FillKind{"none": NoFill, "ffill": ForwardFill, "linear": LinearFill}
+
+// ParseFillKind reads one of none, ffill and linear.
+func ParseFillKind(str string) (FillKind, error) {
+	if kind, ok := fillKinds[str]; ok {
+		return kind, nil
+	}
+	return NoFill, errors.Errorf("unknown fill policy %q", str)
+}
+
+// Align puts the prices of the histories, by ticker, on a common time axis filling the gaps with the given policy.
+func Align(histories map[string]PriceHistory, options AlignOptions) AlignedPrices {
+	location := options.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	series := make(map[string]PriceSeries, len(histories))
+	for ticker, history := range histories {
+		prices := history.Prices
+		if options.Resolution != nil {
+			prices = prices.Resample(*options.Resolution, location, LastPrice)
+		}
+		series[ticker] = NewPriceSeries(prices)
+	}
+
+	result := AlignedPrices{Times: axis(series, options.Axis), Prices: make(map[string][]*float64, len(series))}
+	for ticker, s := range series {
+		values := make([]*float64, len(result.Times))
+		for i, t := range result.Times {
+			values[i] = options.Fill.valueAt(s, t)
+		}
+		result.Prices[ticker] = values
+	}
+	return result
+}
+
+// valueAt returns the price of the series at the given time, filled according to the policy, nil when there is none.
+func (policy FillPolicy) valueAt(series PriceSeries, t time.Time) *float64 {
+	previous, found := series.Latest(t)
+	if !found {
+		return nil
+	}
+	if previous.Time.Equal(t) {
+		return &previous.Price
+	}
+
+	switch policy.Kind {
+	case ForwardFill:
+		if policy.MaxAge > 0 {
+			if previous, found = series.LatestWithin(t, policy.MaxAge); !found {
+				return nil
+			}
+		}
+		return &previous.Price
+	case LinearFill:
+		next, found := series.Next(t)
+		if !found {
+			return nil
+		}
+		ratio := float64(t.Sub(previous.Time)) / float64(next.Time.Sub(previous.Time))
+		value := previous.Price + (next.Price-previous.Price)*ratio
+		return &value
+	default:
+		return nil
+	}
+}
+
+// axis returns the times of the series, all of them or the ones they share, ordered.
+func axis(series map[string]PriceSeries, kind AxisKind) []time.Time {
+	counts := make(map[int64]int)
+	times := make(map[int64]time.Time)
+	for _, s := range series {
+		for i := 0; i < s.Len(); i++ {
+			t := s.At(i).Time
+			if i > 0 && s.At(i-1).Time.Equal(t) {
+				continue
+			}
+			key := t.UnixNano()
+			counts[key]++
+			if _, seen := times[key]; !seen {
+				times[key] = t
+			}
+		}
+	}
+
+	result := []time.Time{}
+	for key, t := range times {
+		if kind == IntersectionAxis && counts[key] < len(series) {
+			continue
+		}
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Before(result[j])
+	})
+	return result
+}
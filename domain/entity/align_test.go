@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func price(value float64) *float64 {
+	return &value
+}
+
+func alignTestHistories() map[string]PriceHistory {
+	return map[string]PriceHistory{
+		"LON:ANP": {Prices: PriceList{{Price: 10, Time: may(7, 16)}, {Price: 14, Time: may(11, 16)}}},
+		"NYSE:SQ": {Prices: PriceList{{Price: 80, Time: may(7, 16)}, {Price: 81, Time: may(8, 16)}, {Price: 82, Time: may(9, 16)}}},
+	}
+}
+
+func Test_Align_WHEN_NoFill_THEN_LeaveGapsEmpty(t *testing.T) {
+	result := Align(alignTestHistories(), AlignOptions{})
+
+	assert.Equal(t, []time.Time{may(7, 16), may(8, 16), may(9, 16), may(11, 16)}, result.Times)
+	assert.Equal(t, []*float64{price(10), nil, nil, price(14)}, result.Prices["LON:ANP"])
+	assert.Equal(t, []*float64{price(80), price(81), price(82), nil}, result.Prices["NYSE:SQ"])
+}
+
+func Test_Align_WHEN_ForwardFill_THEN_FillUpToMaxAge(t *testing.T) {
+	result := Align(alignTestHistories(), AlignOptions{Fill: FillPolicy{Kind: ForwardFill, MaxAge: 36 * time.Hour}})
+
+	assert.Equal(t, []*float64{price(10), price(10), nil, price(14)}, result.Prices["LON:ANP"])
+	assert.Equal(t, []*float64{price(80), price(81), price(82), nil}, result.Prices["NYSE:SQ"])
+}
+
+func Test_Align_WHEN_LinearFill_THEN_Interpolate(t *testing.T) {
+	result := Align(alignTestHistories(), AlignOptions{Fill: FillPolicy{Kind: LinearFill}})
+
+	assert.Equal(t, []*float64{price(10), price(11), price(12), price(14)}, result.Prices["LON:ANP"])
+	assert.Equal(t, []*float64{price(80), price(81), price(82), nil}, result.Prices["NYSE:SQ"], "no prices after the last one")
+}
+
+func Test_Align_WHEN_CommonAxisAndResolution_THEN_KeepSharedDays(t *testing.T) {
+	histories := alignTestHistories()
+	histories["NYSE:SQ"] = PriceHistory{Prices: PriceList{{Price: 80, Time: may(7, 20)}, {Price: 81, Time: may(8, 20)}}}
+	day := Resolution{Count: 1, Unit: Day}
+
+	result := Align(histories, AlignOptions{Resolution: &day, Axis: IntersectionAxis})
+
+	assert.Equal(t, []time.Time{may(7, 0)}, result.Times)
+	assert.Equal(t, []*float64{price(10)}, result.Prices["LON:ANP"])
+	assert.Equal(t, []*float64{price(80)}, result.Prices["NYSE:SQ"])
+}
@@ -0,0 +1,132 @@
+package entity
+
+import (
+	"sort"
+	"time"
+)
+
+type (
+	// Bar defines the open, high, low and close prices of the period starting at Time, along with the volume traded.
+	// Volume is zero when the source does not know it.
+	Bar struct {
+		Time   time.Time `json:"time"`
+		Open   float64   `json:"open"`
+		High   float64   `json:"high"`
+		Low    float64   `json:"low"`
+		Close  float64   `json:"close"`
+		Volume float64   `json:"volume,omitempty"`
+	}
+
+	// BarList is a list of Bars.
+	BarList []Bar
+)
+
+// FilterByInterval returns a new BarList containing only the Bars starting in the given DateInterval.
+// Lists in chronological order are binary searched.
+func (bl BarList) FilterByInterval(interval DateInterval) BarList {
+	if bl.sorted() {
+		return bl.between(interval.dayBounds())
+	}
+	var result = BarList{}
+	for _, bar := range bl {
+		if interval.Contains(bar.Time) {
+			result = append(result, bar)
+		}
+	}
+	return result
+}
+
+// FilterIn returns a new BarList containing only the Bars starting in the exchange-local days of the given DateInterval.
+// Lists in chronological order are binary searched.
+func (bl BarList) FilterIn(interval DateInterval, calendar Calendar) BarList {
+	if bl.sorted() {
+		return bl.between(interval.Bounds(calendar))
+	}
+	var result = BarList{}
+	for _, bar := range bl {
+		if interval.ContainsIn(bar.Time, calendar) {
+			result = append(result, bar)
+		}
+	}
+	return result
+}
+
+// sorted tells if the bars are in chronological order.
+func (bl BarList) sorted() bool {
+	return sort.SliceIsSorted(bl, func(i, j int) bool {
+		return bl[i].Time.Before(bl[j].Time)
+	})
+}
+
+// between returns a copy of the bars starting from lower included to upper excluded, which are expected in
+// chronological order.
+func (bl BarList) between(lower time.Time, upper time.Time) BarList {
+	start := sort.Search(len(bl), func(i int) bool {
+		return !bl[i].Time.Before(lower)
+	})
+	end := sort.Search(len(bl), func(i int) bool {
+		return !bl[i].Time.Before(upper)
+	})
+	if end < start {
+		end = start
+	}
+	result := make(BarList, end-start)
+	copy(result, bl[start:end])
+	return result
+}
+
+// Closes returns the close prices of the bars.
+func (bl BarList) Closes() PriceList {
+	result := make(PriceList, len(bl))
+	for i, bar := range bl {
+		result[i] = PricePoint{Price: bar.Close, Time: bar.Time}
+	}
+	return result
+}
+
+// Bars turns every PricePoint into a Bar opening, peaking and closing at its price, for sources knowing nothing more.
+func (pl PriceList) Bars() BarList {
+	result := make(BarList, len(pl))
+	for i, price := range pl {
+		result[i] = Bar{Time: price.Time, Open: price.Price, High: price.Price, Low: price.Price, Close: price.Price}
+	}
+	return result
+}
+
+// FilterByInterval returns a copy of the history with only the prices and bars in the given DateInterval,
+// whose days are the exchange-local ones of the ticker market.
+func (ph PriceHistory) FilterByInterval(interval DateInterval) PriceHistory {
+	calendar := ph.Calendar()
+	ph.Prices = ph.Prices.FilterIn(interval, calendar)
+	if ph.Bars != nil {
+		ph.Bars = ph.Bars.FilterIn(interval, calendar)
+	}
+	return ph
+}
+
+// On gets the PricePoint at a given exchange-local day of the ticker market, see PriceSeries.OnIn.
+func (ph PriceHistory) On(day time.Time) (PricePoint, error) {
+	return ph.Prices.OnIn(day, ph.Calendar())
+}
+
+// Calendar returns the Calendar of the ticker market.
+func (ph PriceHistory) Calendar() Calendar {
+	return CalendarOf(ph.Ticker.Market)
+}
+
+// BarsOnly returns a copy of the history with bars in place of prices, built from the prices when the source gave no bars,
+// in which case they are flagged as SyntheticBars.
+func (ph PriceHistory) BarsOnly() PriceHistory {
+	if len(ph.Bars) == 0 {
+		ph.Bars = ph.Prices.Bars()
+		ph.SyntheticBars = true
+	}
+	ph.Prices = nil
+	return ph
+}
+
+// PricesOnly returns a copy of the history without bars.
+func (ph PriceHistory) PricesOnly() PriceHistory {
+	ph.Bars = nil
+	return ph
+}
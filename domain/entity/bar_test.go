@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PriceHistory_BarsOnly_WHEN_NoBars_THEN_BuildThemFromPrices(t *testing.T) {
+	may6 := time.Date(2018, time.May, 6, 0, 0, 0, 0, time.UTC)
+	history := PriceHistory{Prices: PriceList{{Price: 200, Time: may6}}}
+
+	result := history.BarsOnly()
+
+	assert.Nil(t, result.Prices)
+	assert.Equal(t, BarList{{Time: may6, Open: 200, High: 200, Low: 200, Close: 200}}, result.Bars)
+	assert.True(t, result.SyntheticBars)
+	assert.False(t, PriceHistory{Bars: result.Bars}.BarsOnly().SyntheticBars, "the source gave the bars")
+}
+
+func Test_PriceHistory_FilterByInterval_THEN_FilterPricesAndBars(t *testing.T) {
+	may6 := time.Date(2018, time.May, 6, 0, 0, 0, 0, time.UTC)
+	may8 := time.Date(2018, time.May, 8, 0, 0, 0, 0, time.UTC)
+	bars := BarList{{Time: may6, Open: 1, High: 3, Low: 1, Close: 2, Volume: 100}, {Time: may8, Open: 2, High: 2, Low: 1, Close: 1}}
+	history := PriceHistory{Prices: bars.Closes(), Bars: bars}
+	interval, _ := NewDateInterval(may6, may6.Add(time.Hour))
+
+	result := history.FilterByInterval(interval)
+
+	assert.Equal(t, PriceList{{Price: 2, Time: may6}}, result.Prices)
+	assert.Equal(t, bars[:1], result.Bars)
+	assert.Len(t, history.Bars, 2, "the original history should be left alone")
+}
@@ -0,0 +1,224 @@
+package entity
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const dayLayout = "2006-01-02"
+
+// Calendar tells when an exchange trades: its time zone, the session hours and the holidays.
+// Days are exchange-local, i.e. they start at midnight in the exchange time zone.
+// Weekends are never trading days.
+type Calendar struct {
+	// Location is the exchange time zone.
+	Location *time.Location
+	// Open and Close are the session hours, as time since the start of the day.
+	Open  time.Duration
+	Close time.Duration
+
+	holidays map[string]bool
+}
+
+// Calendars maps Ticker.Market to the Calendar of the exchange.
+type Calendars map[string]Calendar
+
+// UTCCalendar is used for markets we know nothing about: UTC days, trading all day long on weekdays.
+var UTCCalendar = NewCalendar(time.UTC, 0, 24*time.Hour, nil)
+
+var (
+	calendarsMutex sync.RWMutex
+	calendars      = defaultCalendars()
+)
+
+// NewCalendar creates a Calendar with the given holidays, taken as dates regardless of their location.
+func NewCalendar(location *time.Location, open time.Duration, close time.Duration, holidays []time.Time) Calendar {
+	calendar := Calendar{Location: location, Open: open, Close: close, holidays: make(map[string]bool, len(holidays))}
+	for _, holiday := range holidays {
+		calendar.holidays[holiday.Format(dayLayout)] = true
+	}
+	return calendar
+}
+
+// CalendarOf returns the Calendar of the given market, UTCCalendar when unknown.
+func CalendarOf(market string) Calendar {
+	calendarsMutex.RLock()
+	defer calendarsMutex.RUnlock()
+	if calendar, ok := calendars[market]; ok {
+		return calendar
+	}
+	return UTCCalendar
+}
+
+// SetCalendars adds the given calendars to the known ones, replacing the ones of the same markets.
+func SetCalendars(added Calendars) {
+	calendarsMutex.Lock()
+	defer calendarsMutex.Unlock()
+	merged := make(Calendars, len(calendars)+len(added))
+	for market, calendar := range calendars {
+		merged[market] = calendar
+	}
+	for market, calendar := range added {
+		merged[market] = calendar
+	}
+	calendars = merged
+}
+
+// ReadCalendars reads calendars from JSON like:
+//
+//	{"LON": {"timeZone": "Europe/London", "open": "08:00", "close": "16:30", "holidays": ["2018-12-25", "2018-12-26"]}}
+func ReadCalendars(reader io.Reader) (Calendars, error) {
+	var data map[string]struct {
+		TimeZone string   `json:"timeZone"`
+		Open     string   `json:"open"`
+		Close    string   `json:"close"`
+		Holidays []string `json:"holidays"`
+	}
+	if err := json.NewDecoder(reader).Decode(&data); err != nil {
+		return nil, errors.Wrap(err, "unable to decode calendars")
+	}
+
+	result := make(Calendars, len(data))
+	for market, c := range data {
+		location, err := time.LoadLocation(c.TimeZone)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid time zone for market %s", market)
+		}
+		open, err := clock(c.Open)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid open for market %s", market)
+		}
+		close, err := clock(c.Close)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid close for market %s", market)
+		}
+
+		holidays := make([]time.Time, len(c.Holidays))
+		for i, str := range c.Holidays {
+			if holidays[i], err = time.Parse(dayLayout, str); err != nil {
+				return nil, errors.Wrapf(err, "invalid holiday for market %s", market)
+			}
+		}
+		result[market] = NewCalendar(location, open, close, holidays)
+	}
+	return result, nil
+}
+
+// Day returns the start of the exchange day t falls in.
+func (c Calendar) Day(t time.Time) time.Time {
+	return c.DateOf(t.In(c.Location))
+}
+
+// DateOf returns the start of the exchange day with the same date as the given one, whatever its location.
+// E.g. DateOf(2018-05-08 00:00 UTC) is 2018-05-08 00:00 in New York for NASDAQ.
+func (c Calendar) DateOf(date time.Time) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, c.Location)
+}
+
+// SameDay tells if both times fall in the same exchange day.
+func (c Calendar) SameDay(t1 time.Time, t2 time.Time) bool {
+	return c.Day(t1).Equal(c.Day(t2))
+}
+
+// IsTradingDay tells if the exchange trades on the day t falls in.
+func (c Calendar) IsTradingDay(t time.Time) bool {
+	day := c.Day(t)
+	weekday := day.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday && !c.holidays[day.Format(dayLayout)]
+}
+
+// IsHoliday tells if the day t falls in is a holiday, weekends aside.
+func (c Calendar) IsHoliday(t time.Time) bool {
+	return c.holidays[c.Day(t).Format(dayLayout)]
+}
+
+// Session returns when the exchange opens and closes on the day t falls in, trading day or not.
+func (c Calendar) Session(t time.Time) (open time.Time, close time.Time) {
+	day := c.Day(t)
+	return addClock(day, c.Open), addClock(day, c.Close)
+}
+
+// IsOpen tells if the exchange is trading at the given time.
+func (c Calendar) IsOpen(t time.Time) bool {
+	if !c.IsTradingDay(t) {
+		return false
+	}
+	open, close := c.Session(t)
+	return !t.Before(open) && t.Before(close)
+}
+
+// PreviousTradingDay returns the start of the last trading day before the day t falls in.
+func (c Calendar) PreviousTradingDay(t time.Time) time.Time {
+	day := c.Day(t).AddDate(0, 0, -1)
+	for !c.IsTradingDay(day) {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// NextTradingDay returns the start of the first trading day after the day t falls in.
+func (c Calendar) NextTradingDay(t time.Time) time.Time {
+	day := c.Day(t).AddDate(0, 0, 1)
+	for !c.IsTradingDay(day) {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// addClock adds a time of day to the start of a day, going by the clock rather than elapsed time on DST changes.
+func addClock(day time.Time, clock time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, int(clock), day.Location())
+}
+
+func clock(str string) (time.Duration, error) {
+	t, err := time.Parse("15:04", str)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// defaultCalendars knows the time zones and session hours of the main markets, along with the holidays of the London
+// and US ones, see ukHolidays and usHolidays. Other holidays have to be loaded with SetCalendars.
+// Markets whose time zone is missing on this system are left out.
+func defaultCalendars() Calendars {
+	uk := builtInHolidays(ukHolidays, ukClosures)
+	us := builtInHolidays(usHolidays, usClosures)
+	sessions := []struct {
+		markets     []string
+		timeZone    string
+		open, close string
+		holidays    []time.Time
+	}{
+		{[]string{"LON"}, "Europe/London", "08:00", "16:30", uk},
+		{[]string{"NASDAQ", "NYSE", "NYSEARCA", "NYSEAMERICAN"}, "America/New_York", "09:30", "16:00", us},
+		{[]string{"TSE"}, "America/Toronto", "09:30", "16:00", nil},
+		{[]string{"ETR", "FRA"}, "Europe/Berlin", "09:00", "17:30", nil},
+		{[]string{"EPA"}, "Europe/Paris", "09:00", "17:30", nil},
+		{[]string{"AMS"}, "Europe/Amsterdam", "09:00", "17:30", nil},
+		{[]string{"BIT"}, "Europe/Rome", "09:00", "17:30", nil},
+		{[]string{"SWX"}, "Europe/Zurich", "09:00", "17:30", nil},
+		{[]string{"TYO"}, "Asia/Tokyo", "09:00", "15:00", nil},
+		{[]string{"HKG"}, "Asia/Hong_Kong", "09:30", "16:00", nil},
+		{[]string{"ASX"}, "Australia/Sydney", "10:00", "16:00", nil},
+	}
+
+	result := Calendars{}
+	for _, session := range sessions {
+		location, err := time.LoadLocation(session.timeZone)
+		if err != nil {
+			continue
+		}
+		open, _ := clock(session.open)
+		close, _ := clock(session.close)
+		for _, market := range session.markets {
+			result[market] = NewCalendar(location, open, close, session.holidays)
+		}
+	}
+	return result
+}
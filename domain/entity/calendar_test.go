@@ -0,0 +1,115 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const londonCalendar = `{"LON": {"timeZone": "Europe/London", "open": "08:00", "close": "16:30", "holidays": ["2018-05-07"]}}`
+
+func Test_ReadCalendars(t *testing.T) {
+	calendars, err := ReadCalendars(strings.NewReader(londonCalendar))
+
+	if assert.NoError(t, err) {
+		lon := calendars["LON"]
+		london, _ := time.LoadLocation("Europe/London")
+		assert.Equal(t, london, lon.Location)
+		assert.Equal(t, 8*time.Hour, lon.Open)
+		assert.Equal(t, 16*time.Hour+30*time.Minute, lon.Close)
+		assert.True(t, lon.IsHoliday(time.Date(2018, time.May, 7, 12, 0, 0, 0, london)))
+	}
+}
+
+func Test_ReadCalendars_WHEN_Invalid_THEN_ReturnError(t *testing.T) {
+	invalid := []string{
+		`{"LON": {"timeZone": "Europe/Nowhere", "open": "08:00", "close": "16:30"}}`,
+		`{"LON": {"timeZone": "Europe/London", "open": "8am", "close": "16:30"}}`,
+		`{"LON": {"timeZone": "Europe/London", "open": "08:00", "close": "16:30", "holidays": ["07-05-2018"]}}`,
+		`[]`,
+	}
+	for _, str := range invalid {
+		_, err := ReadCalendars(strings.NewReader(str))
+		assert.Error(t, err, str)
+	}
+}
+
+func Test_Calendar_TradingDays(t *testing.T) {
+	calendars, _ := ReadCalendars(strings.NewReader(londonCalendar))
+	lon := calendars["LON"]
+	london := lon.Location
+
+	// Friday 4 May 2018, followed by a weekend and the early May bank holiday.
+	friday := time.Date(2018, time.May, 4, 15, 0, 0, 0, time.UTC)
+	assert.True(t, lon.IsTradingDay(friday))
+	assert.False(t, lon.IsTradingDay(friday.AddDate(0, 0, 1)))
+	assert.False(t, lon.IsTradingDay(friday.AddDate(0, 0, 3)))
+	assert.Equal(t, time.Date(2018, time.May, 8, 0, 0, 0, 0, london), lon.NextTradingDay(friday))
+	assert.Equal(t, time.Date(2018, time.May, 4, 0, 0, 0, 0, london), lon.PreviousTradingDay(friday.AddDate(0, 0, 4)))
+
+	open, close := lon.Session(friday)
+	assert.Equal(t, time.Date(2018, time.May, 4, 8, 0, 0, 0, london), open)
+	assert.Equal(t, time.Date(2018, time.May, 4, 16, 30, 0, 0, london), close)
+	assert.True(t, lon.IsOpen(friday))
+	assert.False(t, lon.IsOpen(close))
+}
+
+func Test_defaultCalendars_THEN_KnowHolidays(t *testing.T) {
+	holidays := func(calendar Calendar, year int) []string {
+		var result []string
+		for day := time.Date(year, time.January, 1, 12, 0, 0, 0, calendar.Location); day.Year() == year; day = day.AddDate(0, 0, 1) {
+			if calendar.IsHoliday(day) {
+				result = append(result, day.Format(dayLayout))
+			}
+		}
+		return result
+	}
+	lon, nyse := CalendarOf("LON"), CalendarOf("NYSE")
+
+	assert.Equal(t, []string{"2018-01-01", "2018-03-30", "2018-04-02", "2018-05-07", "2018-05-28", "2018-08-27", "2018-12-25", "2018-12-26"}, holidays(lon, 2018))
+	assert.Equal(t, []string{"2020-01-01", "2020-04-10", "2020-04-13", "2020-05-08", "2020-05-25", "2020-08-31", "2020-12-25", "2020-12-28"}, holidays(lon, 2020))
+	assert.Equal(t, []string{"2022-01-03", "2022-04-15", "2022-04-18", "2022-05-02", "2022-06-02", "2022-06-03", "2022-08-29", "2022-09-19", "2022-12-26", "2022-12-27"}, holidays(lon, 2022))
+	assert.Equal(t, []string{"2018-01-01", "2018-01-15", "2018-02-19", "2018-03-30", "2018-05-28", "2018-07-04", "2018-09-03", "2018-11-22", "2018-12-05", "2018-12-25"}, holidays(nyse, 2018))
+	assert.Equal(t, []string{"2021-01-01", "2021-01-18", "2021-02-15", "2021-04-02", "2021-05-31", "2021-07-05", "2021-09-06", "2021-11-25", "2021-12-24"}, holidays(nyse, 2021))
+	assert.Equal(t, []string{"2022-01-17", "2022-02-21", "2022-04-15", "2022-05-30", "2022-06-20", "2022-07-04", "2022-09-05", "2022-11-24", "2022-12-26"}, holidays(nyse, 2022))
+}
+
+func Test_Calendar_Day_THEN_UseExchangeLocalDays(t *testing.T) {
+	nasdaq := CalendarOf("NASDAQ")
+	// 8 May 2018 at 01:00 UTC is still 7 May in New York.
+	late := time.Date(2018, time.May, 8, 1, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2018, time.May, 7, 0, 0, 0, 0, nasdaq.Location), nasdaq.Day(late))
+	assert.False(t, nasdaq.SameDay(late, time.Date(2018, time.May, 8, 15, 0, 0, 0, time.UTC)))
+	assert.Equal(t, UTCCalendar, CalendarOf("NOWHERE"))
+}
+
+func Test_DateInterval_ContainsIn(t *testing.T) {
+	nasdaq := CalendarOf("NASDAQ")
+	interval, _ := NewDateInterval(time.Date(2018, time.May, 7, 0, 0, 0, 0, time.UTC), time.Date(2018, time.May, 8, 0, 0, 0, 0, time.UTC))
+
+	assert.True(t, interval.ContainsIn(time.Date(2018, time.May, 9, 1, 0, 0, 0, time.UTC), nasdaq), "8 May in New York")
+	assert.False(t, interval.ContainsIn(time.Date(2018, time.May, 7, 1, 0, 0, 0, time.UTC), nasdaq), "6 May in New York")
+	assert.Len(t, interval.TradingDays(nasdaq), 2)
+}
+
+func Test_PriceHistory_On_THEN_UseExchangeLocalDays(t *testing.T) {
+	nasdaq := CalendarOf("NASDAQ")
+	history := PriceHistory{
+		TickerInfo: TickerInfo{Ticker: Ticker{Market: "NASDAQ", Symbol: "AAPL"}},
+		Prices: PriceList{
+			{Price: 1, Time: time.Date(2018, time.May, 7, 20, 30, 0, 0, nasdaq.Location)},
+			{Price: 2, Time: time.Date(2018, time.May, 8, 16, 0, 0, 0, nasdaq.Location)},
+		},
+	}
+
+	result, err := history.On(time.Date(2018, time.May, 7, 0, 0, 0, 0, time.UTC))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1.0, result.Price)
+	}
+	_, err = history.Prices.On(time.Date(2018, time.May, 7, 0, 0, 0, 0, time.UTC))
+	assert.Error(t, err, "the 7 May 20:30 price in New York is on 8 May in UTC")
+}
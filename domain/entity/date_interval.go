@@ -34,6 +34,36 @@ func (interval DateInterval) Contains(t time.Time) bool {
 	return (t.After(interval.from) || t.Equal(interval.from) || sameDay(t, interval.from)) && (t.Before(interval.to) || t.Equal(interval.to) || sameDay(t, interval.to))
 }
 
+// Bounds returns when the interval starts and ends in exchange-local days, from the start of the from day
+// to the start of the day after the to one, excluded. Dates are taken regardless of the location of from and to.
+func (interval DateInterval) Bounds(calendar Calendar) (start time.Time, end time.Time) {
+	return calendar.DateOf(interval.from), calendar.DateOf(interval.to).AddDate(0, 0, 1)
+}
+
+// dayBounds returns the start of the from day and the start of the day after the to one, in their own locations.
+func (interval DateInterval) dayBounds() (start time.Time, end time.Time) {
+	from, to := interval.from, interval.to
+	return time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location()), time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, to.Location())
+}
+
+// ContainsIn checks if the provided time falls in one of the exchange-local days of the interval.
+func (interval DateInterval) ContainsIn(t time.Time, calendar Calendar) bool {
+	start, end := interval.Bounds(calendar)
+	return !t.Before(start) && t.Before(end)
+}
+
+// TradingDays returns the start of every trading day in the interval.
+func (interval DateInterval) TradingDays(calendar Calendar) []time.Time {
+	var result []time.Time
+	start, end := interval.Bounds(calendar)
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if calendar.IsTradingDay(day) {
+			result = append(result, day)
+		}
+	}
+	return result
+}
+
 func sameDay(t1 time.Time, t2 time.Time) bool {
 	return t1.Day() == t2.Day() && t1.Month() == t2.Month() && t1.Year() == t2.Year()
 }
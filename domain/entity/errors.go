@@ -1,6 +1,13 @@
 package entity
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
 
 // ErrNothingFound defines an error where no prices can be found for a ticker.
 type ErrNothingFound struct {
@@ -15,3 +22,126 @@ func (err ErrNothingFound) Error() string {
 func NewErrNothingFound(ticker Ticker) ErrNothingFound{
 	return ErrNothingFound{errStr:fmt.Sprintf("Unable to get prices for ticker:%s", ticker)}
 }
+
+// NewErrPortfolioNotFound creates a new ErrNothingFound error for a portfolio that does not exist.
+func NewErrPortfolioNotFound(id string) ErrNothingFound {
+	return ErrNothingFound{errStr: fmt.Sprintf("Unable to find portfolio:%s", id)}
+}
+
+// ErrUpstreamThrottled defines an error where an upstream source is refusing our requests, e.g. rate limiting or asking for a captcha.
+// RetryAfter is how long the source asked us to wait, zero when unknown.
+type ErrUpstreamThrottled struct {
+	errStr     string
+	RetryAfter time.Duration
+}
+
+func (err ErrUpstreamThrottled) Error() string {
+	return err.errStr
+}
+
+// NewErrUpstreamThrottled creates a new ErrUpstreamThrottled error.
+func NewErrUpstreamThrottled(source string, reason string, retryAfter time.Duration) ErrUpstreamThrottled {
+	return ErrUpstreamThrottled{errStr: fmt.Sprintf("Upstream %s throttled: %s", source, reason), RetryAfter: retryAfter}
+}
+
+// ErrUpstreamFailure defines an error where an upstream source could not be reached or answered with an error.
+type ErrUpstreamFailure struct {
+	errStr string
+}
+
+func (err ErrUpstreamFailure) Error() string {
+	return err.errStr
+}
+
+// NewErrUpstreamFailure creates a new ErrUpstreamFailure error.
+func NewErrUpstreamFailure(source string, reason string) ErrUpstreamFailure {
+	return ErrUpstreamFailure{errStr: fmt.Sprintf("Upstream %s failed: %s", source, reason)}
+}
+
+// ErrMalformedData defines an error where a source answered with data we are unable to read.
+type ErrMalformedData struct {
+	errStr string
+}
+
+func (err ErrMalformedData) Error() string {
+	return err.errStr
+}
+
+// NewErrMalformedData creates a new ErrMalformedData error.
+func NewErrMalformedData(source string, reason string) ErrMalformedData {
+	return ErrMalformedData{errStr: fmt.Sprintf("Unable to read data from %s: %s", source, reason)}
+}
+
+// ErrValidation defines an error where a request is not valid, e.g. a parameter is missing.
+type ErrValidation struct {
+	errStr string
+}
+
+func (err ErrValidation) Error() string {
+	return err.errStr
+}
+
+// NewErrValidation creates a new ErrValidation error.
+func NewErrValidation(reason string) ErrValidation {
+	return ErrValidation{errStr: reason}
+}
+
+// ErrConflict defines an error where a change can not be saved as what it changes has been changed meanwhile.
+type ErrConflict struct {
+	errStr string
+}
+
+func (err ErrConflict) Error() string {
+	return err.errStr
+}
+
+// NewErrConflict creates a new ErrConflict error.
+func NewErrConflict(reason string) ErrConflict {
+	return ErrConflict{errStr: reason}
+}
+
+// ErrorKind tells the reason of a failure in terms callers can act upon.
+type ErrorKind string
+
+const (
+	// NotFound means the source has no prices for the ticker.
+	NotFound ErrorKind = "not-found"
+	// UpstreamFailure means a source could not be reached or failed to answer.
+	UpstreamFailure ErrorKind = "upstream-failure"
+	// ParseFailure means a source answered with data we are unable to read.
+	ParseFailure ErrorKind = "parse-failure"
+	// Throttled means a source is refusing our requests for now.
+	Throttled ErrorKind = "throttled"
+	// Timeout means the request ran out of time, or was given up, before the source answered.
+	Timeout ErrorKind = "timeout"
+	// Validation means the request itself is not valid.
+	Validation ErrorKind = "validation"
+	// Conflict means a change was made meanwhile to what the request changes.
+	Conflict ErrorKind = "conflict"
+)
+
+// KindOf tells the kind of an error, looking at its cause.
+// Errors of unknown type are taken as upstream failures, as sources are where prices come from.
+func KindOf(err error) ErrorKind {
+	switch cause := errors.Cause(err).(type) {
+	case ErrNothingFound:
+		return NotFound
+	case ErrMalformedData:
+		return ParseFailure
+	case ErrUpstreamThrottled:
+		return Throttled
+	case ErrValidation:
+		return Validation
+	case ErrConflict:
+		return Conflict
+	case net.Error:
+		if cause.Timeout() {
+			return Timeout
+		}
+	}
+	if cause := errors.Cause(err); cause == context.DeadlineExceeded || cause == context.Canceled {
+		return Timeout
+	}
+
+	return UpstreamFailure
+}
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_KindOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorKind
+	}{
+		{"nothing found", NewErrNothingFound(Ticker{Market: "LON", Symbol: "ANP"}), NotFound},
+		{"malformed data", errors.Wrap(NewErrMalformedData("csv", "line 3"), "unable to read"), ParseFailure},
+		{"throttled", errors.Wrap(NewErrUpstreamThrottled("google finance", "429", time.Second), "unable to read"), Throttled},
+		{"upstream failure", NewErrUpstreamFailure("google finance", "503"), UpstreamFailure},
+		{"deadline", errors.Wrap(context.DeadlineExceeded, "unable to talk to remote server"), Timeout},
+		{"cancelled", context.Canceled, Timeout},
+		{"validation", NewErrValidation("Missing parameter tickers"), Validation},
+		{"conflict", errors.Wrap(NewErrConflict("changed meanwhile"), "unable to save"), Conflict},
+		{"anything else", errors.New("boom"), UpstreamFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, KindOf(tt.err))
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package entity
+
+import "time"
+
+// The years the built-in holidays are computed for.
+const (
+	firstHolidayYear = 2000
+	lastHolidayYear  = 2050
+)
+
+// One-off closures of the US exchanges, e.g. national days of mourning, which no rule gives.
+var usClosures = []string{
+	"2001-09-11", "2001-09-12", "2001-09-13", "2001-09-14", "2004-06-11", "2007-01-02", "2012-10-29", "2012-10-30",
+	"2018-12-05", "2025-01-09",
+}
+
+// One-off bank holidays of England, e.g. royal events, which no rule gives.
+var ukClosures = []string{"2002-06-03", "2011-04-29", "2012-06-05", "2022-06-03", "2022-09-19", "2023-05-08"}
+
+// UK bank holidays moved from their usual Monday to another day, by usual date.
+var ukMoved = map[string]string{
+	"2002-05-27": "2002-06-04",
+	"2012-05-28": "2012-06-04",
+	"2020-05-04": "2020-05-08",
+	"2022-05-30": "2022-06-02",
+}
+
+// builtInHolidays returns the holidays given by the rules for every year from firstHolidayYear to lastHolidayYear,
+// along with the one-off closures. Holidays are dates at midnight UTC, taken regardless of their location.
+func builtInHolidays(rules func(year int) []time.Time, closures []string) []time.Time {
+	var result []time.Time
+	for year := firstHolidayYear; year <= lastHolidayYear; year++ {
+		result = append(result, rules(year)...)
+	}
+	for _, str := range closures {
+		closure, _ := time.Parse(dayLayout, str)
+		result = append(result, closure)
+	}
+	return result
+}
+
+// usHolidays returns the holidays of the NYSE and NASDAQ in the year. Holidays on a Saturday are observed on the
+// Friday before, and on a Sunday on the Monday after, except New Year's Day which is not observed on a Saturday.
+func usHolidays(year int) []time.Time {
+	result := []time.Time{
+		weekdayOf(year, time.January, 3, time.Monday),  // Martin Luther King Jr. Day
+		weekdayOf(year, time.February, 3, time.Monday), // Washington's Birthday
+		easter(year).AddDate(0, 0, -2),                 // Good Friday
+		weekdayOf(year, time.May, -1, time.Monday),     // Memorial Day
+		observed(utcDate(year, time.July, 4)),
+		weekdayOf(year, time.September, 1, time.Monday),  // Labor Day
+		weekdayOf(year, time.November, 4, time.Thursday), // Thanksgiving Day
+		observed(utcDate(year, time.December, 25)),
+	}
+	if newYear := utcDate(year, time.January, 1); newYear.Weekday() != time.Saturday {
+		result = append(result, observed(newYear))
+	}
+	if year >= 2022 {
+		result = append(result, observed(utcDate(year, time.June, 19))) // Juneteenth
+	}
+	return result
+}
+
+// ukHolidays returns the bank holidays of England in the year, when the London Stock Exchange is closed.
+// Holidays on a weekend are observed on the next weekday that is not already a holiday.
+func ukHolidays(year int) []time.Time {
+	result := []time.Time{
+		nextWeekday(utcDate(year, time.January, 1)),
+		easter(year).AddDate(0, 0, -2), // Good Friday
+		easter(year).AddDate(0, 0, 1),  // Easter Monday
+	}
+	for _, day := range []time.Time{
+		weekdayOf(year, time.May, 1, time.Monday),     // Early May bank holiday
+		weekdayOf(year, time.May, -1, time.Monday),    // Spring bank holiday
+		weekdayOf(year, time.August, -1, time.Monday), // Summer bank holiday
+	} {
+		if moved, ok := ukMoved[day.Format(dayLayout)]; ok {
+			day, _ = time.Parse(dayLayout, moved)
+		}
+		result = append(result, day)
+	}
+
+	christmas := nextWeekday(utcDate(year, time.December, 25))
+	boxingDay := nextWeekday(christmas.AddDate(0, 0, 1))
+	return append(result, christmas, boxingDay)
+}
+
+// easter returns Easter Sunday of the Gregorian calendar, see the anonymous Gregorian algorithm.
+func easter(year int) time.Time {
+	a, b, c := year%19, year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return utcDate(year, time.Month(month), day)
+}
+
+// weekdayOf returns the n-th weekday of the month, counting from its end when n is negative.
+func weekdayOf(year int, month time.Month, n int, weekday time.Weekday) time.Time {
+	if n < 0 {
+		last := utcDate(year, month+1, 0)
+		back := (int(last.Weekday()) - int(weekday) + 7) % 7
+		return last.AddDate(0, 0, 7*(n+1)-back)
+	}
+	first := utcDate(year, month, 1)
+	ahead := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, 7*(n-1)+ahead)
+}
+
+// observed moves a holiday on a Saturday to the Friday before and one on a Sunday to the Monday after.
+func observed(day time.Time) time.Time {
+	switch day.Weekday() {
+	case time.Saturday:
+		return day.AddDate(0, 0, -1)
+	case time.Sunday:
+		return day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// nextWeekday returns the day itself when it is a weekday, the Monday after otherwise.
+func nextWeekday(day time.Time) time.Time {
+	for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+func utcDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
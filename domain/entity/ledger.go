@@ -0,0 +1,269 @@
+package entity
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TransactionKind tells what a Transaction does to the holdings and the cash of a portfolio.
+type TransactionKind string
+
+const (
+	// Buy adds Quantity of Ticker, paying Amount.
+	Buy TransactionKind = "buy"
+	// Sell removes Quantity of Ticker, receiving Amount.
+	Sell TransactionKind = "sell"
+	// Dividend receives Amount from Ticker.
+	Dividend TransactionKind = "dividend"
+	// Fee pays Amount.
+	Fee TransactionKind = "fee"
+	// Deposit brings Amount into the portfolio from outside of it.
+	Deposit TransactionKind = "deposit"
+	// Withdrawal takes Amount out of the portfolio.
+	Withdrawal TransactionKind = "withdrawal"
+)
+
+// quantityTolerance is how far from zero a quantity held can be and still count as nothing held.
+const quantityTolerance = 1e-9
+
+type (
+	// Transaction defines a change to the holdings or the cash of a portfolio.
+	// Amount is never negative, Kind tells whether it is paid or received.
+	// Ticker is required by buys, sells and dividends, optional for fees and not allowed otherwise.
+	Transaction struct {
+		Time     time.Time       `json:"time"`
+		Kind     TransactionKind `json:"kind"`
+		Ticker   *Ticker         `json:"ticker,omitempty"`
+		Quantity float64         `json:"quantity,omitempty"`
+		Amount   float64         `json:"amount"`
+	}
+
+	// Ledger defines the transactions of a portfolio, in chronological order, all amounts in Currency.
+	// Version counts the changes saved, so that stores can refuse saving over a change made since the ledger was read.
+	Ledger struct {
+		PortfolioID  string        `json:"portfolioId"`
+		Currency     string        `json:"currency"`
+		Transactions []Transaction `json:"transactions"`
+		Version      int           `json:"version"`
+	}
+
+	// DailyValue defines the value of a portfolio at the end of a day, cash included.
+	// Flow is the money deposited, negative when withdrawn, since the previous DailyValue up to the end of the day.
+	DailyValue struct {
+		Time  time.Time `json:"time"`
+		Value float64   `json:"value"`
+		Cash  float64   `json:"cash"`
+		Flow  float64   `json:"flow"`
+	}
+)
+
+// Add returns a copy of the ledger with the transactions added, in chronological order.
+// Transactions at the same time keep the order they were added in.
+func (l Ledger) Add(transactions ...Transaction) Ledger {
+	all := make([]Transaction, 0, len(l.Transactions)+len(transactions))
+	all = append(append(all, l.Transactions...), transactions...)
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Time.Before(all[j].Time)
+	})
+	l.Transactions = all
+	return l
+}
+
+// Tickers returns the tickers bought or sold, once each.
+func (l Ledger) Tickers() []Ticker {
+	seen := map[Ticker]bool{}
+	var result []Ticker
+	for _, transaction := range l.Transactions {
+		if transaction.quantityChange() != 0 && !seen[*transaction.Ticker] {
+			seen[*transaction.Ticker] = true
+			result = append(result, *transaction.Ticker)
+		}
+	}
+	return result
+}
+
+// Validate checks every transaction is well formed and no more is ever sold than held.
+func (l Ledger) Validate() error {
+	if l.PortfolioID == "" {
+		return NewErrValidation("Missing portfolio id")
+	}
+	if l.Currency == "" {
+		return NewErrValidation(fmt.Sprintf("Missing currency of the ledger of portfolio %s", l.PortfolioID))
+	}
+
+	quantities := map[Ticker]float64{}
+	for i, transaction := range l.Transactions {
+		if err := transaction.validate(); err != nil {
+			return NewErrValidation(fmt.Sprintf("Invalid transaction %d of portfolio %s: %s", i, l.PortfolioID, err))
+		}
+		if i > 0 && transaction.Time.Before(l.Transactions[i-1].Time) {
+			return NewErrValidation(fmt.Sprintf("Transactions of portfolio %s are not in chronological order", l.PortfolioID))
+		}
+		if transaction.quantityChange() == 0 {
+			continue
+		}
+		transaction.applyTo(quantities)
+		if quantities[*transaction.Ticker] < 0 {
+			return NewErrValidation(fmt.Sprintf("Transaction %d of portfolio %s sells more %s than held", i, l.PortfolioID, *transaction.Ticker))
+		}
+	}
+	return nil
+}
+
+// HeldTickers returns the tickers held at the end of at least one of the given days, once each.
+func (l Ledger) HeldTickers(days []time.Time) []Ticker {
+	seen := map[Ticker]bool{}
+	var result []Ticker
+	tickers := l.Tickers()
+	l.replay(days, func(day time.Time, quantities map[Ticker]float64, cash float64, flow float64) error {
+		for _, ticker := range tickers {
+			if quantities[ticker] != 0 && !seen[ticker] {
+				seen[ticker] = true
+				result = append(result, ticker)
+			}
+		}
+		return nil
+	})
+	return result
+}
+
+// Values returns the value of the portfolio at the end of the given days, which are expected in chronological order,
+// replaying the transactions of the ledger, which is expected to be valid.
+// Holdings are valued at the last price of the exchange-local day of their ticker, found in the histories by ticker.
+// Only the tickers held on the given days need a history, see HeldTickers.
+// Holdings without such a price, or priced in a currency other than the ledger one, give an error.
+func (l Ledger) Values(days []time.Time, histories map[string]PriceHistory) ([]DailyValue, error) {
+	series := make(map[Ticker]PriceSeries)
+	result := make([]DailyValue, 0, len(days))
+	err := l.replay(days, func(day time.Time, quantities map[Ticker]float64, cash float64, flow float64) error {
+		value := DailyValue{Time: day, Value: cash, Cash: cash, Flow: flow}
+		for ticker, quantity := range quantities {
+			if quantity == 0 {
+				continue
+			}
+			history, ok := histories[ticker.String()]
+			if !ok {
+				return NewErrNothingFound(ticker)
+			}
+			if _, ok := series[ticker]; !ok {
+				if currency, _ := MajorCurrency(history.Currency); history.Currency != "" && currency != l.Currency {
+					return NewErrValidation(fmt.Sprintf("Ticker %s is priced in %s, not in the ledger currency %s", ticker, history.Currency, l.Currency))
+				}
+				series[ticker] = history.Series()
+			}
+			price, err := series[ticker].OnIn(day, history.Calendar())
+			if err != nil {
+				return NewErrNothingFound(ticker)
+			}
+			_, factor := MajorCurrency(history.Currency)
+			value.Value += quantity * price.Price / factor
+		}
+		result = append(result, value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result) > 0 {
+		// Transactions before the first day are what the portfolio starts with, not flows.
+		result[0].Flow = 0
+	}
+	return result, nil
+}
+
+// replay calls end with the quantities held and the cash at the end of each of the days, along with the money
+// deposited since the previous one, stopping at the first error.
+func (l Ledger) replay(days []time.Time, end func(day time.Time, quantities map[Ticker]float64, cash float64, flow float64) error) error {
+	quantities := map[Ticker]float64{}
+	cash, next := 0.0, 0
+	for _, day := range days {
+		flow := 0.0
+		for ; next < len(l.Transactions) && l.Transactions[next].Time.Before(day.AddDate(0, 0, 1)); next++ {
+			transaction := l.Transactions[next]
+			cash += transaction.cashChange()
+			flow += transaction.flow()
+			if transaction.quantityChange() != 0 {
+				transaction.applyTo(quantities)
+			}
+		}
+		if err := end(day, quantities, cash, flow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate checks the transaction has what its kind needs.
+func (t Transaction) validate() error {
+	if t.Time.IsZero() {
+		return errors.New("missing time")
+	}
+	if t.Amount < 0 || t.Quantity < 0 {
+		return errors.New("negative amount or quantity")
+	}
+	switch t.Kind {
+	case Buy, Sell:
+		if t.Ticker == nil || t.Quantity == 0 {
+			return errors.Errorf("%s without ticker or quantity", t.Kind)
+		}
+	case Dividend:
+		if t.Ticker == nil {
+			return errors.New("dividend without ticker")
+		}
+	case Fee, Deposit, Withdrawal:
+		if t.Ticker != nil && t.Kind != Fee {
+			return errors.Errorf("%s with a ticker", t.Kind)
+		}
+	default:
+		return errors.Errorf("unknown kind %q", t.Kind)
+	}
+	return nil
+}
+
+// quantityChange returns how the quantity held of the ticker changes.
+func (t Transaction) quantityChange() float64 {
+	switch t.Kind {
+	case Buy:
+		return t.Quantity
+	case Sell:
+		return -t.Quantity
+	default:
+		return 0
+	}
+}
+
+// applyTo changes the quantity held of the ticker, dropping the residue left by summing fractional quantities so that
+// selling everything held leaves exactly zero.
+func (t Transaction) applyTo(quantities map[Ticker]float64) {
+	quantity := quantities[*t.Ticker] + t.quantityChange()
+	if math.Abs(quantity) < quantityTolerance {
+		quantity = 0
+	}
+	quantities[*t.Ticker] = quantity
+}
+
+// cashChange returns how the cash of the portfolio changes.
+func (t Transaction) cashChange() float64 {
+	switch t.Kind {
+	case Sell, Dividend, Deposit:
+		return t.Amount
+	default:
+		return -t.Amount
+	}
+}
+
+// flow returns the money brought into the portfolio from outside, negative when taken out.
+func (t Transaction) flow() float64 {
+	switch t.Kind {
+	case Deposit:
+		return t.Amount
+	case Withdrawal:
+		return -t.Amount
+	default:
+		return 0
+	}
+}
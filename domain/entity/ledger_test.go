@@ -0,0 +1,106 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ledgerTestHistories() map[string]PriceHistory {
+	newYork := CalendarOf("NYSE").Location
+	close := func(day int, price float64) PricePoint {
+		return PricePoint{Price: price, Time: time.Date(2018, time.May, day, 16, 0, 0, 0, newYork)}
+	}
+	return map[string]PriceHistory{"NYSE:SQ": {
+		TickerInfo: TickerInfo{Ticker: sq, Currency: "USD"},
+		Prices:     PriceList{close(4, 50), close(7, 55), close(8, 60), close(9, 66)},
+	}}
+}
+
+func ledgerTestLedger() Ledger {
+	return Ledger{PortfolioID: "isa", Currency: "USD"}.Add(
+		Transaction{Time: may(8, 10), Kind: Deposit, Amount: 500},
+		Transaction{Time: may(6, 10), Kind: Deposit, Amount: 1000},
+		Transaction{Time: may(6, 11), Kind: Buy, Ticker: &sq, Quantity: 10, Amount: 500},
+		Transaction{Time: may(9, 12), Kind: Dividend, Ticker: &sq, Amount: 10},
+	)
+}
+
+func Test_Ledger_Values(t *testing.T) {
+	ledger := ledgerTestLedger()
+	days := []time.Time{may(7, 0), may(8, 0), may(9, 0)}
+
+	result, err := ledger.Values(days, ledgerTestHistories())
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []DailyValue{
+			{Time: may(7, 0), Value: 1050, Cash: 500},
+			{Time: may(8, 0), Value: 1600, Cash: 1000, Flow: 500},
+			{Time: may(9, 0), Value: 1670, Cash: 1010},
+		}, result)
+	}
+	assert.Equal(t, []Ticker{sq}, ledger.Tickers())
+}
+
+func Test_Ledger_Values_WHEN_NoPrice_THEN_ReturnError(t *testing.T) {
+	early := ledgerTestLedger().Add(Transaction{Time: may(2, 10), Kind: Buy, Ticker: &sq, Quantity: 1, Amount: 50})
+	_, err := early.Values([]time.Time{may(3, 0), may(7, 0)}, ledgerTestHistories())
+	assert.IsType(t, ErrNothingFound{}, err, "the ticker is held before its first price")
+
+	_, err = ledgerTestLedger().Values([]time.Time{may(7, 0)}, map[string]PriceHistory{})
+	assert.IsType(t, ErrNothingFound{}, err)
+
+	ledger := ledgerTestLedger()
+	ledger.Currency = "GBP"
+	_, err = ledger.Values([]time.Time{may(7, 0)}, ledgerTestHistories())
+	assert.IsType(t, ErrValidation{}, err)
+}
+
+func Test_Ledger_Values_WHEN_SoldBeforeTheDays_THEN_NeedNoPrice(t *testing.T) {
+	delisted := Ticker{Market: "NYSE", Symbol: "GONE"}
+	ledger := ledgerTestLedger().Add(
+		Transaction{Time: may(1, 10), Kind: Buy, Ticker: &delisted, Quantity: 5, Amount: 50},
+		Transaction{Time: may(2, 10), Kind: Sell, Ticker: &delisted, Quantity: 5, Amount: 60},
+	)
+	days := []time.Time{may(7, 0), may(8, 0)}
+
+	result, err := ledger.Values(days, ledgerTestHistories())
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1060.0, result[0].Value)
+	}
+	assert.Equal(t, []Ticker{sq}, ledger.HeldTickers(days))
+	assert.Equal(t, []Ticker{delisted, sq}, ledger.HeldTickers([]time.Time{may(1, 0), may(7, 0)}))
+}
+
+func Test_Ledger_WHEN_FractionalSharesAllSold_THEN_HoldNothing(t *testing.T) {
+	fractional := Ticker{Market: "NASDAQ", Symbol: "X"}
+	ledger := ledgerTestLedger().Add(
+		Transaction{Time: may(1, 10), Kind: Buy, Ticker: &fractional, Quantity: 0.3, Amount: 30},
+		Transaction{Time: may(2, 10), Kind: Sell, Ticker: &fractional, Quantity: 0.1, Amount: 10},
+		Transaction{Time: may(2, 11), Kind: Sell, Ticker: &fractional, Quantity: 0.2, Amount: 20},
+	)
+
+	assert.NoError(t, ledger.Validate())
+	assert.Equal(t, []Ticker{sq}, ledger.HeldTickers([]time.Time{may(7, 0)}))
+	_, err := ledger.Values([]time.Time{may(7, 0)}, ledgerTestHistories())
+	assert.NoError(t, err)
+}
+
+func Test_Ledger_Validate(t *testing.T) {
+	assert.NoError(t, ledgerTestLedger().Validate())
+
+	invalid := []Ledger{
+		{Currency: "USD"},
+		{PortfolioID: "isa"},
+		ledgerTestLedger().Add(Transaction{Time: may(10, 0), Kind: Sell, Ticker: &sq, Quantity: 11, Amount: 700}),
+		ledgerTestLedger().Add(Transaction{Time: may(10, 0), Kind: Buy, Quantity: 1, Amount: 70}),
+		ledgerTestLedger().Add(Transaction{Time: may(10, 0), Kind: Deposit, Amount: -1}),
+		ledgerTestLedger().Add(Transaction{Time: may(10, 0), Kind: "gift", Amount: 1}),
+		ledgerTestLedger().Add(Transaction{Kind: Deposit, Amount: 1}),
+	}
+	for _, ledger := range invalid {
+		assert.IsType(t, ErrValidation{}, ledger.Validate(), "%+v", ledger)
+	}
+}
@@ -0,0 +1,144 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	// Holding defines a position in a Ticker.
+	// CostBasis is the total paid for the Quantity held, in the major unit of the ticker currency, e.g. GBP for GBX.
+	Holding struct {
+		Ticker    Ticker  `json:"ticker"`
+		Quantity  float64 `json:"quantity"`
+		CostBasis float64 `json:"costBasis"`
+	}
+
+	// Portfolio defines holdings along with cash balances by currency.
+	Portfolio struct {
+		ID       string             `json:"id"`
+		Name     string             `json:"name,omitempty"`
+		Holdings []Holding          `json:"holdings"`
+		Cash     map[string]float64 `json:"cash,omitempty"`
+	}
+
+	// PositionValuation defines the value of a Holding at a CurrentPrice, in the major unit of the ticker currency.
+	// Weight is the share of the position in the total value of that currency, cash included.
+	PositionValuation struct {
+		Holding
+		Name          string    `json:"name,omitempty"`
+		Currency      string    `json:"currency"`
+		Price         float64   `json:"price"`
+		PriceTime     time.Time `json:"priceTime"`
+		Value         float64   `json:"value"`
+		UnrealizedPnL float64   `json:"unrealizedPnL"`
+		Weight        float64   `json:"weight"`
+	}
+
+	// CurrencyTotals defines the totals of the positions and cash in a currency. Value includes the cash.
+	CurrencyTotals struct {
+		Positions     float64 `json:"positions"`
+		Cash          float64 `json:"cash"`
+		Value         float64 `json:"value"`
+		CostBasis     float64 `json:"costBasis"`
+		UnrealizedPnL float64 `json:"unrealizedPnL"`
+	}
+
+	// Valuation defines the value of the positions of a Portfolio and its totals by currency.
+	// Currencies are never converted into one another, as there are no exchange rates to do it.
+	Valuation struct {
+		Positions []PositionValuation       `json:"positions"`
+		Totals    map[string]CurrencyTotals `json:"totals"`
+	}
+)
+
+// minorUnits maps currencies quoted in a fraction of another one to that currency and how many make one of it.
+var minorUnits = map[string]struct {
+	major  string
+	factor float64
+}{
+	"GBX": {"GBP", 100},
+	"GBp": {"GBP", 100},
+	"ZAc": {"ZAR", 100},
+	"ILA": {"ILS", 100},
+}
+
+// MajorCurrency returns the currency a price in the given one is valued in, along with the factor to divide it by,
+// e.g. GBP and 100 for GBX. Other currencies are returned as they are, with a factor of 1.
+func MajorCurrency(currency string) (string, float64) {
+	if unit, ok := minorUnits[currency]; ok {
+		return unit.major, unit.factor
+	}
+	return currency, 1
+}
+
+// Tickers returns the tickers held, once each.
+func (p Portfolio) Tickers() []Ticker {
+	seen := map[Ticker]bool{}
+	var result []Ticker
+	for _, holding := range p.Holdings {
+		if !seen[holding.Ticker] {
+			seen[holding.Ticker] = true
+			result = append(result, holding.Ticker)
+		}
+	}
+	return result
+}
+
+// Validate checks the portfolio has an ID and no negative quantities or cost basis.
+func (p Portfolio) Validate() error {
+	if p.ID == "" {
+		return NewErrValidation("Missing portfolio id")
+	}
+	for _, holding := range p.Holdings {
+		if holding.Quantity < 0 || holding.CostBasis < 0 {
+			return NewErrValidation(fmt.Sprintf("Invalid holding of %s in portfolio %s", holding.Ticker, p.ID))
+		}
+	}
+	return nil
+}
+
+// Value values the holdings at the given prices, by ticker, along with the cash.
+// Holdings without a price are left out of the positions and the totals.
+func (p Portfolio) Value(prices map[string]CurrentPrice) Valuation {
+	result := Valuation{Positions: []PositionValuation{}, Totals: map[string]CurrencyTotals{}}
+	for currency, cash := range p.Cash {
+		currency, factor := MajorCurrency(currency)
+		totals := result.Totals[currency]
+		totals.Cash += cash / factor
+		totals.Value += cash / factor
+		result.Totals[currency] = totals
+	}
+
+	for _, holding := range p.Holdings {
+		price, ok := prices[holding.Ticker.String()]
+		if !ok {
+			continue
+		}
+		currency, factor := MajorCurrency(price.Currency)
+		position := PositionValuation{
+			Holding:   holding,
+			Name:      price.Name,
+			Currency:  currency,
+			Price:     price.Price / factor,
+			PriceTime: price.Time,
+		}
+		position.Value = holding.Quantity * position.Price
+		position.UnrealizedPnL = position.Value - holding.CostBasis
+		result.Positions = append(result.Positions, position)
+
+		totals := result.Totals[currency]
+		totals.Positions += position.Value
+		totals.Value += position.Value
+		totals.CostBasis += holding.CostBasis
+		totals.UnrealizedPnL += position.UnrealizedPnL
+		result.Totals[currency] = totals
+	}
+
+	for i, position := range result.Positions {
+		if total := result.Totals[position.Currency].Value; total != 0 {
+			result.Positions[i].Weight = position.Value / total
+		}
+	}
+	return result
+}
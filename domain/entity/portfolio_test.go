@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	anp = Ticker{Market: "LON", Symbol: "ANP"}
+	sq  = Ticker{Market: "NYSE", Symbol: "SQ"}
+)
+
+func Test_Portfolio_Value(t *testing.T) {
+	portfolio := Portfolio{
+		ID:       "isa",
+		Holdings: []Holding{{Ticker: anp, Quantity: 100, CostBasis: 150}, {Ticker: sq, Quantity: 2, CostBasis: 200}},
+		Cash:     map[string]float64{"GBP": 50},
+	}
+	prices := map[string]CurrentPrice{
+		"LON:ANP": {TickerInfo: TickerInfo{Ticker: anp, Currency: "GBX"}, Price: 200, Time: may(7, 16)},
+		"NYSE:SQ": {TickerInfo: TickerInfo{Ticker: sq, Currency: "USD"}, Price: 80, Time: may(7, 20)},
+	}
+
+	result := portfolio.Value(prices)
+
+	if assert.Len(t, result.Positions, 2) {
+		anpPosition := result.Positions[0]
+		assert.Equal(t, "GBP", anpPosition.Currency)
+		assert.Equal(t, 2.0, anpPosition.Price, "pence are converted to pounds")
+		assert.Equal(t, 200.0, anpPosition.Value)
+		assert.Equal(t, 50.0, anpPosition.UnrealizedPnL)
+		assert.Equal(t, 0.8, anpPosition.Weight)
+
+		sqPosition := result.Positions[1]
+		assert.Equal(t, -40.0, sqPosition.UnrealizedPnL)
+		assert.Equal(t, 1.0, sqPosition.Weight)
+	}
+	assert.Equal(t, map[string]CurrencyTotals{
+		"GBP": {Positions: 200, Cash: 50, Value: 250, CostBasis: 150, UnrealizedPnL: 50},
+		"USD": {Positions: 160, Value: 160, CostBasis: 200, UnrealizedPnL: -40},
+	}, result.Totals)
+}
+
+func Test_Portfolio_Value_WHEN_NoPrice_THEN_LeaveHoldingOut(t *testing.T) {
+	portfolio := Portfolio{ID: "isa", Holdings: []Holding{{Ticker: anp, Quantity: 100, CostBasis: 150}}}
+
+	result := portfolio.Value(map[string]CurrentPrice{})
+
+	assert.Empty(t, result.Positions)
+	assert.Empty(t, result.Totals)
+}
+
+func Test_Portfolio_Validate(t *testing.T) {
+	assert.NoError(t, Portfolio{ID: "isa", Holdings: []Holding{{Ticker: anp, Quantity: 1}}}.Validate())
+	assert.IsType(t, ErrValidation{}, Portfolio{}.Validate())
+	assert.IsType(t, ErrValidation{}, Portfolio{ID: "isa", Holdings: []Holding{{Ticker: anp, Quantity: -1}}}.Validate())
+}
@@ -3,6 +3,7 @@ package entity
 import (
 	"time"
 	"fmt"
+	"encoding/json"
 )
 
 type (
@@ -23,17 +24,47 @@ type (
 	// CurrentPrice defines the current price.
 	CurrentPrice struct {
 		TickerInfo
-		Price float64   `json:"price"`
-		Time  time.Time `json:"time"`
+		Price     float64    `json:"price"`
+		Time      time.Time  `json:"time"`
+		Source    string     `json:"source,omitempty"`
+		Freshness *Freshness `json:"freshness,omitempty"`
 	}
 
 	// PriceHistory defines the price history for a Ticker.
+	// Prices are close prices, Bars are there too when the source knows more than that.
+	// SyntheticBars tells the bars were built from the prices, as the source had none, so they are no real OHLC.
 	PriceHistory struct {
 		TickerInfo
-		Prices PriceList `json:"prices"`
+		Prices        PriceList  `json:"prices,omitempty"`
+		Bars          BarList    `json:"bars,omitempty"`
+		SyntheticBars bool       `json:"syntheticBars,omitempty"`
+		Source        string     `json:"source,omitempty"`
+		Freshness     *Freshness `json:"freshness,omitempty"`
+	}
+
+	// Freshness tells when prices were fetched from their source.
+	// Stale prices are older than we would like, they are served because the source failed to give newer ones.
+	Freshness struct {
+		FetchedAt time.Time
+		Age       time.Duration
+		Stale     bool
 	}
 )
 
 func (t Ticker) String() string {
 	return fmt.Sprintf("%s:%s", t.Market, t.Symbol)
 }
+
+// NewFreshness creates the Freshness of prices fetched at the given time.
+func NewFreshness(fetchedAt time.Time, now time.Time, stale bool) *Freshness {
+	return &Freshness{FetchedAt: fetchedAt, Age: now.Sub(fetchedAt), Stale: stale}
+}
+
+// MarshalJSON writes the age in whole seconds, easier to use than nanoseconds.
+func (f Freshness) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		FetchedAt  time.Time `json:"fetchedAt"`
+		AgeSeconds int64     `json:"ageSeconds"`
+		Stale      bool      `json:"stale"`
+	}{f.FetchedAt, int64(f.Age / time.Second), f.Stale})
+}
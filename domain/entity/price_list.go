@@ -1,9 +1,8 @@
 package entity
 
 import (
-	"time"
 	"sort"
-	"github.com/pkg/errors"
+	"time"
 )
 
 type (
@@ -17,32 +16,39 @@ type (
 	PriceList []PricePoint
 )
 
-// On gets the PricePoint at a given point in time.
+// On gets the PricePoint at a given point in time, however old it is.
+// The list is left as it is, and only copied and sorted when not in chronological order already. Build a PriceSeries
+// once to look up many days in such lists, and use PriceSeries.LatestWithin to bound the age.
 func (pl PriceList) On(day time.Time) (PricePoint, error) {
-	if len(pl) == 0 {
-		return PricePoint{}, errors.New("unable to get price on date for empty price history")
-	}
-
-	sort.Slice(pl, func(i, j int) bool {
-		return pl[i].Time.Before(pl[j].Time)
-	})
+	return pl.series().On(day)
+}
 
-	dayToSearch := time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 59, 0, time.UTC)
-	var i int
-	for i = 0; i < len(pl) && pl[i].Time.Before(dayToSearch); i++ {
+// OnIn gets the PricePoint at a given exchange-local day, see PriceSeries.OnIn.
+func (pl PriceList) OnIn(day time.Time, calendar Calendar) (PricePoint, error) {
+	return pl.series().OnIn(day, calendar)
+}
 
+// FilterIn returns a new PriceList containing only the PricePoints in the exchange-local days of the given DateInterval.
+// Lists in chronological order are binary searched.
+func (pl PriceList) FilterIn(interval DateInterval, calendar Calendar) PriceList {
+	if pl.sorted() {
+		return PriceSeries{prices: pl}.BetweenIn(interval, calendar).Prices()
 	}
-
-	if i == 0 {
-		return PricePoint{}, errors.New("nothing found")
+	var result = PriceList{}
+	for _, price := range pl {
+		if interval.ContainsIn(price.Time, calendar) {
+			result = append(result, price)
+		}
 	}
-
-	// we should check if the day param is too far ahead
-	return pl[i-1], nil
+	return result
 }
 
 // FilterByInterval returns a new PriceList containing only the PricePoints in the given DateInterval.
+// Lists in chronological order are binary searched.
 func (pl PriceList) FilterByInterval(interval DateInterval) PriceList {
+	if pl.sorted() {
+		return PriceSeries{prices: pl}.Between(interval).Prices()
+	}
 	var result = PriceList{}
 	for _, price := range pl {
 		if interval.Contains(price.Time) {
@@ -52,3 +58,18 @@ func (pl PriceList) FilterByInterval(interval DateInterval) PriceList {
 	return result
 }
 
+// sorted tells if the prices are in chronological order.
+func (pl PriceList) sorted() bool {
+	return sort.SliceIsSorted(pl, func(i, j int) bool {
+		return pl[i].Time.Before(pl[j].Time)
+	})
+}
+
+// series returns the prices as a PriceSeries, sharing them when they are in chronological order already.
+// The series must not outlive the call using it, as the list can be changed afterwards.
+func (pl PriceList) series() PriceSeries {
+	if pl.sorted() {
+		return PriceSeries{prices: pl}
+	}
+	return NewPriceSeries(pl)
+}
@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PriceSeries is a list of prices sorted by time once, when created, and never changed afterwards.
+// Lookups are binary searches and slices share the prices of the series they come from, so that long histories
+// can be queried many times cheaply. The zero value is an empty series.
+type PriceSeries struct {
+	prices PriceList
+}
+
+// NewPriceSeries creates a series from a copy of the prices, sorted by time. Prices at the same time keep their order.
+func NewPriceSeries(prices PriceList) PriceSeries {
+	sorted := make(PriceList, len(prices))
+	copy(sorted, prices)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+	return PriceSeries{prices: sorted}
+}
+
+// Len returns the number of prices in the series.
+func (s PriceSeries) Len() int {
+	return len(s.prices)
+}
+
+// At returns the i-th price of the series, from the oldest one.
+func (s PriceSeries) At(i int) PricePoint {
+	return s.prices[i]
+}
+
+// Latest returns the last price at or before the given time, found is false when there is none.
+func (s PriceSeries) Latest(t time.Time) (price PricePoint, found bool) {
+	i := sort.Search(len(s.prices), func(i int) bool {
+		return s.prices[i].Time.After(t)
+	})
+	if i == 0 {
+		return PricePoint{}, false
+	}
+	return s.prices[i-1], true
+}
+
+// Next returns the first price after the given time, found is false when there is none.
+func (s PriceSeries) Next(t time.Time) (price PricePoint, found bool) {
+	i := sort.Search(len(s.prices), func(i int) bool {
+		return s.prices[i].Time.After(t)
+	})
+	if i == len(s.prices) {
+		return PricePoint{}, false
+	}
+	return s.prices[i], true
+}
+
+// LatestWithin returns the last price at or before the given time, as long as it is not older than maxAge.
+func (s PriceSeries) LatestWithin(t time.Time, maxAge time.Duration) (price PricePoint, found bool) {
+	price, found = s.Latest(t)
+	if !found || t.Sub(price.Time) > maxAge {
+		return PricePoint{}, false
+	}
+	return price, true
+}
+
+// On gets the PricePoint at a given day, i.e. the last one before the end of that day in UTC.
+func (s PriceSeries) On(day time.Time) (PricePoint, error) {
+	return s.OnIn(day, UTCCalendar)
+}
+
+// OnIn gets the PricePoint at a given exchange-local day, i.e. the last one before the end of that day.
+// The date of day is taken regardless of its location, see Calendar.DateOf.
+// Non trading days get the price of the last trading day before them.
+func (s PriceSeries) OnIn(day time.Time, calendar Calendar) (PricePoint, error) {
+	if len(s.prices) == 0 {
+		return PricePoint{}, errors.New("unable to get price on date for empty price history")
+	}
+
+	end := calendar.DateOf(day).AddDate(0, 0, 1)
+	i := sort.Search(len(s.prices), func(i int) bool {
+		return !s.prices[i].Time.Before(end)
+	})
+	if i == 0 {
+		return PricePoint{}, errors.New("nothing found")
+	}
+	return s.prices[i-1], nil
+}
+
+// Between returns the part of the series in the interval, whole days included like DateInterval.Contains does.
+// The result shares its prices with the series, nothing is copied.
+func (s PriceSeries) Between(interval DateInterval) PriceSeries {
+	return s.between(interval.dayBounds())
+}
+
+// BetweenIn returns the part of the series in the exchange-local days of the interval, see DateInterval.ContainsIn.
+// The result shares its prices with the series, nothing is copied.
+func (s PriceSeries) BetweenIn(interval DateInterval, calendar Calendar) PriceSeries {
+	return s.between(interval.Bounds(calendar))
+}
+
+// between returns the part of the series from lower included to upper excluded.
+func (s PriceSeries) between(lower time.Time, upper time.Time) PriceSeries {
+	start := sort.Search(len(s.prices), func(i int) bool {
+		return !s.prices[i].Time.Before(lower)
+	})
+	end := sort.Search(len(s.prices), func(i int) bool {
+		return !s.prices[i].Time.Before(upper)
+	})
+	if end < start {
+		end = start
+	}
+	return PriceSeries{prices: s.prices[start:end:end]}
+}
+
+// Prices returns a copy of the prices in the series, which callers are free to change.
+func (s PriceSeries) Prices() PriceList {
+	result := make(PriceList, len(s.prices))
+	copy(result, s.prices)
+	return result
+}
+
+// Series returns the prices of the history as a PriceSeries.
+func (ph PriceHistory) Series() PriceSeries {
+	return NewPriceSeries(ph.Prices)
+}
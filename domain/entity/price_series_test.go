@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func may(day int, hour int) time.Time {
+	return time.Date(2018, time.May, day, hour, 0, 0, 0, time.UTC)
+}
+
+func Test_PriceList_On_THEN_LeaveListAlone(t *testing.T) {
+	list := PriceList{{Price: 3, Time: may(9, 16)}, {Price: 1, Time: may(7, 16)}, {Price: 2, Time: may(8, 16)}}
+	original := append(PriceList(nil), list...)
+
+	result, err := list.On(may(8, 0))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2.0, result.Price)
+	}
+	assert.Equal(t, original, list)
+}
+
+func Test_PriceSeries_Lookups(t *testing.T) {
+	series := NewPriceSeries(PriceList{{Price: 3, Time: may(9, 16)}, {Price: 1, Time: may(7, 16)}, {Price: 2, Time: may(8, 16)}})
+
+	assert.Equal(t, 3, series.Len())
+	assert.Equal(t, PricePoint{Price: 1, Time: may(7, 16)}, series.At(0))
+
+	latest, found := series.Latest(may(8, 17))
+	assert.True(t, found)
+	assert.Equal(t, 2.0, latest.Price)
+	latest, found = series.Latest(may(8, 16))
+	assert.True(t, found)
+	assert.Equal(t, 2.0, latest.Price, "a price at the given time is the latest one")
+	_, found = series.Latest(may(7, 15))
+	assert.False(t, found)
+
+	on, err := series.On(may(10, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, on.Price)
+	_, err = series.On(may(6, 0))
+	assert.Error(t, err)
+	_, err = PriceSeries{}.On(may(6, 0))
+	assert.Error(t, err)
+}
+
+func Test_PriceSeries_Between_THEN_MatchFilterByInterval(t *testing.T) {
+	list := PriceList{{Price: 1, Time: may(6, 0)}, {Price: 2, Time: may(6, 15)}, {Price: 3, Time: may(7, 16)}, {Price: 4, Time: may(8, 13)}, {Price: 5, Time: may(9, 10)}}
+	series := NewPriceSeries(list)
+	intervals := [][2]time.Time{
+		{may(6, 0), may(9, 0)},
+		{may(6, 12), may(8, 0)},
+		{may(7, 0), may(7, 23)},
+		{may(10, 0), may(12, 0)},
+		{may(1, 0), may(5, 0)},
+	}
+
+	// Out of order lists are scanned rather than binary searched.
+	unsorted := append(PriceList{list[4]}, list[:4]...)
+
+	for _, bounds := range intervals {
+		interval, _ := NewDateInterval(bounds[0], bounds[1])
+		assert.Equal(t, list.FilterByInterval(interval), series.Between(interval).Prices(), "interval %s", interval)
+		assert.Equal(t, list.FilterByInterval(interval), NewPriceSeries(unsorted.FilterByInterval(interval)).Prices(), "interval %s", interval)
+		assert.Equal(t, list.FilterIn(interval, UTCCalendar), NewPriceSeries(unsorted.FilterIn(interval, UTCCalendar)).Prices(), "interval %s", interval)
+		assert.Equal(t, list.FilterIn(interval, UTCCalendar).Bars(), list.Bars().FilterIn(interval, UTCCalendar), "interval %s", interval)
+		assert.Equal(t, list.FilterByInterval(interval).Bars(), list.Bars().FilterByInterval(interval), "interval %s", interval)
+	}
+}
+
+func Test_PriceSeries_Prices_THEN_ReturnCopy(t *testing.T) {
+	series := NewPriceSeries(PriceList{{Price: 1, Time: may(6, 0)}})
+
+	series.Prices()[0].Price = 10
+
+	assert.Equal(t, 1.0, series.At(0).Price)
+}
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 	"github.com/stretchr/testify/assert"
@@ -74,3 +75,14 @@ func dateAtMidnightUTC(year int, month time.Month, day int) time.Time {
 	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
 }
 
+
+func Test_Freshness_MarshalJSON_THEN_AgeInSeconds(t *testing.T) {
+	fetchedAt := time.Date(2018, time.May, 5, 12, 0, 0, 0, time.UTC)
+	freshness := NewFreshness(fetchedAt, fetchedAt.Add(90*time.Second+time.Millisecond), true)
+
+	result, err := json.Marshal(CurrentPrice{Price: 12.5, Time: fetchedAt, Freshness: freshness})
+
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(result), `"freshness":{"fetchedAt":"2018-05-05T12:00:00Z","ageSeconds":90,"stale":true}`)
+	}
+}
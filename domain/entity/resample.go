@@ -0,0 +1,223 @@
+package entity
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// TimeUnit is the unit of a Resolution.
+	TimeUnit int
+
+	// Resolution defines the length of the buckets prices are resampled into, e.g. 15 minutes or 1 week.
+	// Buckets are aligned to the start of the day, the week (on Monday) or the month in the time zone used for resampling.
+	Resolution struct {
+		Count int
+		Unit  TimeUnit
+	}
+
+	// Aggregation tells which price stands for all the prices in a bucket.
+	Aggregation int
+)
+
+// Units of a Resolution.
+const (
+	Minute TimeUnit = iota
+	Hour
+	Day
+	Week
+	Month
+)
+
+const (
+	// LastPrice keeps the last price of every bucket, i.e. its close.
+	LastPrice Aggregation = iota
+	// FirstPrice keeps the first price of every bucket, i.e. its open.
+	FirstPrice
+	// MeanPrice averages the prices of every bucket.
+	MeanPrice
+)
+
+var unitSymbols = map[TimeUnit]string{Minute: "m", Hour: "h", Day: "d", Week: "w", Month: "mo"}
+
+// maxResolutionCount bounds the count of a Resolution, far above any useful one, so that bucket arithmetic never overflows.
+const maxResolutionCount = 10000
+
+const minutesPerDay = 24 * 60
+
+var resolutionPattern = regexp.MustCompile(`^([0-9]*)(m|h|d|w|mo)$`)
+
+// ParseResolution reads a count followed by a unit among m (minutes), h (hours), d (days), w (weeks) and mo (months),
+// e.g. 5m or 1w. The count defaults to 1. Minute and hour counts have to divide a day, e.g. 7h is refused.
+func ParseResolution(str string) (Resolution, error) {
+	match := resolutionPattern.FindStringSubmatch(str)
+	if match == nil {
+		return Resolution{}, errors.Errorf("unknown resolution %q", str)
+	}
+
+	count := 1
+	if match[1] != "" {
+		var err error
+		if count, err = strconv.Atoi(match[1]); err != nil || count > maxResolutionCount {
+			return Resolution{}, errors.Errorf("resolution %q is too long", str)
+		}
+	}
+	if count < 1 {
+		return Resolution{}, errors.Errorf("unknown resolution %q", str)
+	}
+	for unit, symbol := range unitSymbols {
+		if symbol != match[2] {
+			continue
+		}
+		// Minute and hour buckets start over every day, so they have to split a day evenly.
+		if (unit == Minute && minutesPerDay%count != 0) || (unit == Hour && 24%count != 0) {
+			return Resolution{}, errors.Errorf("resolution %q does not divide a day", str)
+		}
+		return Resolution{Count: count, Unit: unit}, nil
+	}
+	return Resolution{}, errors.Errorf("unknown resolution %q", str)
+}
+
+func (r Resolution) String() string {
+	return fmt.Sprintf("%d%s", r.Count, unitSymbols[r.Unit])
+}
+
+// BucketStart returns the start of the bucket the time falls in, in the given location.
+func (r Resolution) BucketStart(t time.Time, location *time.Location) time.Time {
+	t = t.In(location)
+	year, month, day := t.Date()
+	count := r.Count
+	if count < 1 {
+		count = 1
+	}
+
+	switch r.Unit {
+	case Minute:
+		minutes := floor(t.Hour()*60+t.Minute(), count)
+		return time.Date(year, month, day, 0, minutes, 0, 0, location)
+	case Hour:
+		return time.Date(year, month, day, floor(t.Hour(), count), 0, 0, 0, location)
+	case Day:
+		return dateOf(floor(daysSinceEpoch(year, month, day), count), location)
+	case Week:
+		// 1970-01-01 was a Thursday, weeks are counted from Monday 1969-12-29.
+		weeks := floor(daysSinceEpoch(year, month, day)+3, 7) / 7
+		return dateOf(floor(weeks, count)*7-3, location)
+	default:
+		months := floor(year*12+int(month)-1, count)
+		return time.Date(months/12, time.Month(months%12+1), 1, 0, 0, 0, 0, location)
+	}
+}
+
+// Resample groups the prices into buckets of the given resolution and keeps one price per bucket, timed at the bucket start.
+func (pl PriceList) Resample(resolution Resolution, location *time.Location, aggregation Aggregation) PriceList {
+	result := PriceList{}
+	for _, bucket := range pl.buckets(resolution, location) {
+		price := bucket.prices[len(bucket.prices)-1].Price
+		switch aggregation {
+		case FirstPrice:
+			price = bucket.prices[0].Price
+		case MeanPrice:
+			sum := 0.0
+			for _, p := range bucket.prices {
+				sum += p.Price
+			}
+			price = sum / float64(len(bucket.prices))
+		}
+		result = append(result, PricePoint{Price: price, Time: bucket.start})
+	}
+	return result
+}
+
+// ResampleBars groups the prices into buckets of the given resolution and builds a Bar from each one.
+func (pl PriceList) ResampleBars(resolution Resolution, location *time.Location) BarList {
+	return pl.Bars().Resample(resolution, location)
+}
+
+// Resample merges the bars into bars of the given resolution: first open, highest high, lowest low, last close, total volume.
+func (bl BarList) Resample(resolution Resolution, location *time.Location) BarList {
+	sorted := bl
+	if !bl.sorted() {
+		sorted = make(BarList, len(bl))
+		copy(sorted, bl)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Time.Before(sorted[j].Time)
+		})
+	}
+
+	result := BarList{}
+	for _, bar := range sorted {
+		start := resolution.BucketStart(bar.Time, location)
+		last := len(result) - 1
+		if last < 0 || !result[last].Time.Equal(start) {
+			bar.Time = start
+			result = append(result, bar)
+			continue
+		}
+		merged := &result[last]
+		if bar.High > merged.High {
+			merged.High = bar.High
+		}
+		if bar.Low < merged.Low {
+			merged.Low = bar.Low
+		}
+		merged.Close = bar.Close
+		merged.Volume += bar.Volume
+	}
+	return result
+}
+
+// Resample returns a copy of the history with its prices resampled with the given aggregation and its bars merged.
+// Bars are built from the prices when the history has none, so that resampled histories always have them, flagged as
+// SyntheticBars.
+func (ph PriceHistory) Resample(resolution Resolution, location *time.Location, aggregation Aggregation) PriceHistory {
+	bars := ph.Bars
+	if len(bars) == 0 {
+		bars = ph.Prices.Bars()
+		ph.SyntheticBars = true
+	}
+	ph.Prices = ph.Prices.Resample(resolution, location, aggregation)
+	ph.Bars = bars.Resample(resolution, location)
+	return ph
+}
+
+// A group of prices sharing a bucket.
+type bucket struct {
+	start  time.Time
+	prices PriceList
+}
+
+// buckets groups the prices by bucket, in chronological order.
+func (pl PriceList) buckets(resolution Resolution, location *time.Location) []bucket {
+	var result []bucket
+	for _, price := range pl.series().prices {
+		start := resolution.BucketStart(price.Time, location)
+		if len(result) == 0 || !result[len(result)-1].start.Equal(start) {
+			result = append(result, bucket{start: start})
+		}
+		result[len(result)-1].prices = append(result[len(result)-1].prices, price)
+	}
+	return result
+}
+
+// floor rounds n down to a multiple of count, negative numbers included.
+func floor(n int, count int) int {
+	if n < 0 {
+		return -((-n + count - 1) / count) * count
+	}
+	return n / count * count
+}
+
+func daysSinceEpoch(year int, month time.Month, day int) int {
+	return int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+func dateOf(days int, location *time.Location) time.Time {
+	year, month, day := time.Unix(int64(days)*86400, 0).UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
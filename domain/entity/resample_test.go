@@ -0,0 +1,100 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseResolution(t *testing.T) {
+	tests := []struct {
+		str     string
+		want    Resolution
+		wantErr bool
+	}{
+		{"5m", Resolution{5, Minute}, false},
+		{"1h", Resolution{1, Hour}, false},
+		{"d", Resolution{1, Day}, false},
+		{"1w", Resolution{1, Week}, false},
+		{"3mo", Resolution{3, Month}, false},
+		{"0d", Resolution{}, true},
+		{"10000d", Resolution{10000, Day}, false},
+		{"10001d", Resolution{}, true},
+		{"99999999999999999999d", Resolution{}, true},
+		{"6h", Resolution{6, Hour}, false},
+		{"1440m", Resolution{1440, Minute}, false},
+		{"7h", Resolution{}, true},
+		{"25h", Resolution{}, true},
+		{"48h", Resolution{}, true},
+		{"2000m", Resolution{}, true},
+		{"1y", Resolution{}, true},
+		{"", Resolution{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			got, err := ParseResolution(tt.str)
+			assert.Equal(t, tt.wantErr, err != nil, "unexpected error %v", err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_Resolution_BucketStart(t *testing.T) {
+	london, _ := time.LoadLocation("Europe/London")
+	// Wednesday 16 May 2018, 15:47 UTC, 16:47 in London.
+	at := time.Date(2018, time.May, 16, 15, 47, 12, 0, time.UTC)
+	tests := []struct {
+		resolution Resolution
+		location   *time.Location
+		want       time.Time
+	}{
+		{Resolution{15, Minute}, time.UTC, time.Date(2018, time.May, 16, 15, 45, 0, 0, time.UTC)},
+		{Resolution{1, Hour}, london, time.Date(2018, time.May, 16, 16, 0, 0, 0, london)},
+		{Resolution{6, Hour}, london, time.Date(2018, time.May, 16, 12, 0, 0, 0, london)},
+		{Resolution{1, Day}, time.UTC, time.Date(2018, time.May, 16, 0, 0, 0, 0, time.UTC)},
+		{Resolution{1, Day}, london, time.Date(2018, time.May, 16, 0, 0, 0, 0, london)},
+		{Resolution{1, Week}, time.UTC, time.Date(2018, time.May, 14, 0, 0, 0, 0, time.UTC)},
+		{Resolution{1, Month}, time.UTC, time.Date(2018, time.May, 1, 0, 0, 0, 0, time.UTC)},
+		{Resolution{3, Month}, time.UTC, time.Date(2018, time.April, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.resolution.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.resolution.BucketStart(at, tt.location))
+		})
+	}
+}
+
+func Test_PriceList_Resample(t *testing.T) {
+	prices := PriceList{
+		{Price: 12, Time: time.Date(2018, time.May, 16, 15, 0, 0, 0, time.UTC)},
+		{Price: 10, Time: time.Date(2018, time.May, 16, 9, 0, 0, 0, time.UTC)},
+		{Price: 11, Time: time.Date(2018, time.May, 16, 12, 0, 0, 0, time.UTC)},
+		{Price: 13, Time: time.Date(2018, time.May, 17, 9, 0, 0, 0, time.UTC)},
+	}
+	may16 := time.Date(2018, time.May, 16, 0, 0, 0, 0, time.UTC)
+	may17 := time.Date(2018, time.May, 17, 0, 0, 0, 0, time.UTC)
+	day := Resolution{1, Day}
+
+	assert.Equal(t, PriceList{{Price: 12, Time: may16}, {Price: 13, Time: may17}}, prices.Resample(day, time.UTC, LastPrice))
+	assert.Equal(t, PriceList{{Price: 10, Time: may16}, {Price: 13, Time: may17}}, prices.Resample(day, time.UTC, FirstPrice))
+	assert.Equal(t, PriceList{{Price: 11, Time: may16}, {Price: 13, Time: may17}}, prices.Resample(day, time.UTC, MeanPrice))
+	assert.Equal(t, BarList{
+		{Time: may16, Open: 10, High: 12, Low: 10, Close: 12},
+		{Time: may17, Open: 13, High: 13, Low: 13, Close: 13},
+	}, prices.ResampleBars(day, time.UTC))
+}
+
+func Test_BarList_Resample_THEN_MergeBars(t *testing.T) {
+	monday := time.Date(2018, time.May, 14, 0, 0, 0, 0, time.UTC)
+	bars := BarList{
+		{Time: monday, Open: 10, High: 12, Low: 9, Close: 11, Volume: 100},
+		{Time: monday.AddDate(0, 0, 1), Open: 11, High: 14, Low: 10, Close: 13, Volume: 200},
+		{Time: monday.AddDate(0, 0, 7), Open: 13, High: 13, Low: 12, Close: 12, Volume: 50},
+	}
+
+	assert.Equal(t, BarList{
+		{Time: monday, Open: 10, High: 14, Low: 9, Close: 13, Volume: 300},
+		{Time: monday.AddDate(0, 0, 7), Open: 13, High: 13, Low: 12, Close: 12, Volume: 50},
+	}, bars.Resample(Resolution{1, Week}, time.UTC))
+}
@@ -0,0 +1,254 @@
+// Package indicator computes technical indicators over price lists.
+// Every indicator returns new series, leaving the prices it is given untouched. A series starts at the first price
+// the indicator has enough history for, so it is shorter than the prices, and empty when the period is not positive
+// or longer than the prices.
+package indicator
+
+import (
+	"math"
+	"sort"
+
+	"org.alex859/stockprices/domain/entity"
+)
+
+type (
+	// MACDLines are the lines of the moving average convergence divergence indicator.
+	MACDLines struct {
+		MACD      entity.PriceList
+		Signal    entity.PriceList
+		Histogram entity.PriceList
+	}
+
+	// BollingerBands are a moving average along with bands a number of standard deviations above and below it.
+	BollingerBands struct {
+		Middle entity.PriceList
+		Upper  entity.PriceList
+		Lower  entity.PriceList
+	}
+)
+
+// SMA returns the simple moving average of the prices over the given number of periods.
+func SMA(prices entity.PriceList, period int) entity.PriceList {
+	sorted := entity.NewPriceSeries(prices).Prices()
+	return series(sorted, sma(values(sorted), period), period-1)
+}
+
+// EMA returns the exponential moving average of the prices over the given number of periods,
+// seeded with the simple moving average of the first ones.
+func EMA(prices entity.PriceList, period int) entity.PriceList {
+	sorted := entity.NewPriceSeries(prices).Prices()
+	return series(sorted, ema(values(sorted), period), period-1)
+}
+
+// WMA returns the linearly weighted moving average of the prices over the given number of periods,
+// the latest price weighting the most.
+func WMA(prices entity.PriceList, period int) entity.PriceList {
+	sorted := entity.NewPriceSeries(prices).Prices()
+	return series(sorted, wma(values(sorted), period), period-1)
+}
+
+// RSI returns the relative strength index of the prices over the given number of periods, between 0 and 100,
+// averaging gains and losses with Wilder's smoothing.
+func RSI(prices entity.PriceList, period int) entity.PriceList {
+	sorted := entity.NewPriceSeries(prices).Prices()
+	if len(sorted) < 2 {
+		return entity.PriceList{}
+	}
+
+	gains := make([]float64, len(sorted)-1)
+	losses := make([]float64, len(sorted)-1)
+	for i := 1; i < len(sorted); i++ {
+		change := sorted[i].Price - sorted[i-1].Price
+		gains[i-1] = math.Max(change, 0)
+		losses[i-1] = math.Max(-change, 0)
+	}
+
+	averageGains, averageLosses := wilder(gains, period), wilder(losses, period)
+	result := make([]float64, len(averageGains))
+	for i := range result {
+		switch {
+		case averageLosses[i] == 0 && averageGains[i] == 0:
+			result[i] = 50
+		case averageLosses[i] == 0:
+			result[i] = 100
+		default:
+			result[i] = 100 - 100/(1+averageGains[i]/averageLosses[i])
+		}
+	}
+	return series(sorted, result, period)
+}
+
+// MACD returns the difference between the fast and the slow exponential moving averages of the prices,
+// the exponential moving average of that difference, i.e. the signal, and how far apart they are, i.e. the histogram.
+func MACD(prices entity.PriceList, fast int, slow int, signal int) MACDLines {
+	sorted := entity.NewPriceSeries(prices).Prices()
+	fastValues, slowValues := ema(values(sorted), fast), ema(values(sorted), slow)
+	if len(fastValues) == 0 || len(slowValues) == 0 {
+		return MACDLines{MACD: entity.PriceList{}, Signal: entity.PriceList{}, Histogram: entity.PriceList{}}
+	}
+
+	// Both averages end with the prices, the longer one is ahead.
+	if len(fastValues) > len(slowValues) {
+		fastValues = fastValues[len(fastValues)-len(slowValues):]
+	} else {
+		slowValues = slowValues[len(slowValues)-len(fastValues):]
+	}
+	macd := make([]float64, len(fastValues))
+	for i := range macd {
+		macd[i] = fastValues[i] - slowValues[i]
+	}
+	offset := len(sorted) - len(macd)
+
+	signalValues := ema(macd, signal)
+	histogram := make([]float64, len(signalValues))
+	for i := range histogram {
+		histogram[i] = macd[i+signal-1] - signalValues[i]
+	}
+	return MACDLines{
+		MACD:      series(sorted, macd, offset),
+		Signal:    series(sorted, signalValues, offset+signal-1),
+		Histogram: series(sorted, histogram, offset+signal-1),
+	}
+}
+
+// Bollinger returns the simple moving average of the prices over the given number of periods,
+// along with bands the given number of standard deviations above and below it.
+func Bollinger(prices entity.PriceList, period int, deviations float64) BollingerBands {
+	sorted := entity.NewPriceSeries(prices).Prices()
+	all := values(sorted)
+	middle := sma(all, period)
+	upper := make([]float64, len(middle))
+	lower := make([]float64, len(middle))
+	for i, mean := range middle {
+		variance := 0.0
+		for _, value := range all[i : i+period] {
+			variance += (value - mean) * (value - mean)
+		}
+		deviation := math.Sqrt(variance/float64(period)) * deviations
+		upper[i] = mean + deviation
+		lower[i] = mean - deviation
+	}
+	return BollingerBands{
+		Middle: series(sorted, middle, period-1),
+		Upper:  series(sorted, upper, period-1),
+		Lower:  series(sorted, lower, period-1),
+	}
+}
+
+// ROC returns the rate of change of the prices in percent over the given number of periods.
+// Prices following a zero price have no rate of change and are skipped.
+func ROC(prices entity.PriceList, period int) entity.PriceList {
+	sorted := entity.NewPriceSeries(prices).Prices()
+	result := entity.PriceList{}
+	if period < 1 {
+		return result
+	}
+	for i := period; i < len(sorted); i++ {
+		if previous := sorted[i-period].Price; previous != 0 {
+			result = append(result, entity.PricePoint{Price: 100 * (sorted[i].Price/previous - 1), Time: sorted[i].Time})
+		}
+	}
+	return result
+}
+
+// ATR returns the average true range of the bars over the given number of periods, with Wilder's smoothing.
+// The true range of the first bar, having no close before it, is its high minus its low.
+func ATR(bars entity.BarList, period int) entity.PriceList {
+	sorted := make(entity.BarList, len(bars))
+	copy(sorted, bars)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	ranges := make([]float64, len(sorted))
+	for i, bar := range sorted {
+		ranges[i] = bar.High - bar.Low
+		if i > 0 {
+			previous := sorted[i-1].Close
+			ranges[i] = math.Max(ranges[i], math.Max(math.Abs(bar.High-previous), math.Abs(bar.Low-previous)))
+		}
+	}
+
+	averages := wilder(ranges, period)
+	result := make(entity.PriceList, len(averages))
+	for i, average := range averages {
+		result[i] = entity.PricePoint{Price: average, Time: sorted[i+period-1].Time}
+	}
+	return result
+}
+
+// values returns the prices without their times.
+func values(prices entity.PriceList) []float64 {
+	result := make([]float64, len(prices))
+	for i, price := range prices {
+		result[i] = price.Price
+	}
+	return result
+}
+
+// series puts the times of the prices, starting from offset, back on the values.
+func series(prices entity.PriceList, values []float64, offset int) entity.PriceList {
+	result := make(entity.PriceList, len(values))
+	for i, value := range values {
+		result[i] = entity.PricePoint{Price: value, Time: prices[i+offset].Time}
+	}
+	return result
+}
+
+// sma returns the simple moving averages of the values, the first one being the average of the first period values.
+func sma(values []float64, period int) []float64 {
+	if period < 1 || period > len(values) {
+		return []float64{}
+	}
+	result := make([]float64, len(values)-period+1)
+	sum := 0.0
+	for i, value := range values {
+		sum += value
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			result[i-period+1] = sum / float64(period)
+		}
+	}
+	return result
+}
+
+// ema returns the exponential moving averages of the values, with a smoothing factor of 2 / (period + 1).
+func ema(values []float64, period int) []float64 {
+	return smooth(values, period, 2/float64(period+1))
+}
+
+// wilder returns the moving averages of the values with Wilder's smoothing factor of 1 / period.
+func wilder(values []float64, period int) []float64 {
+	return smooth(values, period, 1/float64(period))
+}
+
+// smooth returns the exponentially smoothed values, seeded with the simple moving average of the first period values.
+func smooth(values []float64, period int, factor float64) []float64 {
+	if period < 1 || period > len(values) {
+		return []float64{}
+	}
+	result := sma(values[:period], period)
+	for _, value := range values[period:] {
+		result = append(result, result[len(result)-1]+factor*(value-result[len(result)-1]))
+	}
+	return result
+}
+
+// wma returns the linearly weighted moving averages of the values.
+func wma(values []float64, period int) []float64 {
+	if period < 1 || period > len(values) {
+		return []float64{}
+	}
+	weights := float64(period*(period+1)) / 2
+	result := make([]float64, len(values)-period+1)
+	for i := range result {
+		sum := 0.0
+		for j, value := range values[i : i+period] {
+			sum += float64(j+1) * value
+		}
+		result[i] = sum / weights
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+package indicator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"org.alex859/stockprices/domain/entity"
+)
+
+func day(d int) time.Time {
+	return time.Date(2018, time.May, d, 16, 0, 0, 0, time.UTC)
+}
+
+// prices returns one price a day from 1 May, in reverse order to check indicators sort them.
+func prices(values ...float64) entity.PriceList {
+	result := make(entity.PriceList, len(values))
+	for i, value := range values {
+		result[len(values)-1-i] = entity.PricePoint{Price: value, Time: day(i + 1)}
+	}
+	return result
+}
+
+func assertValues(t *testing.T, expected []float64, firstDay int, actual entity.PriceList) {
+	if assert.Len(t, actual, len(expected)) {
+		for i, value := range expected {
+			assert.InDelta(t, value, actual[i].Price, 1e-9, "price %d", i)
+			assert.Equal(t, day(firstDay+i), actual[i].Time, "time %d", i)
+		}
+	}
+}
+
+func Test_SMA(t *testing.T) {
+	assertValues(t, []float64{2, 3, 4}, 3, SMA(prices(1, 2, 3, 4, 5), 3))
+	assert.Empty(t, SMA(prices(1, 2), 3))
+	assert.Empty(t, SMA(prices(1, 2), 0))
+}
+
+func Test_EMA(t *testing.T) {
+	// Seeded with the average of 1, 2 and 3, then smoothed by 2 / (3 + 1).
+	assertValues(t, []float64{2, 3, 4.5}, 3, EMA(prices(1, 2, 3, 4, 6), 3))
+}
+
+func Test_WMA(t *testing.T) {
+	assertValues(t, []float64{(1 + 2*2 + 3*3) / 6.0, (2 + 2*3 + 3*7) / 6.0}, 3, WMA(prices(1, 2, 3, 7), 3))
+}
+
+func Test_RSI(t *testing.T) {
+	// Changes are +1, -1, +2, +2: averages of the first two are 0.5 and 0.5, then 1.25 and 0.25, then 1.625 and 0.125.
+	assertValues(t, []float64{50, 100 - 100/6.0, 100 - 100/14.0}, 3, RSI(prices(1, 2, 1, 3, 5), 2))
+	assertValues(t, []float64{100}, 3, RSI(prices(1, 2, 3), 2))
+	assertValues(t, []float64{50}, 3, RSI(prices(1, 1, 1), 2))
+}
+
+func Test_MACD(t *testing.T) {
+	lines := MACD(prices(1, 2, 3, 4, 6, 8), 2, 3, 2)
+
+	// The fast EMA is 1.5, 2.5, 3.5, 5.166.., 7.055.., the slow one 2, 3, 4.5, 6.25.
+	assertValues(t, []float64{0.5, 0.5, 2.0 / 3, 29.0 / 36}, 3, lines.MACD)
+	assertValues(t, []float64{0.5, 11.0 / 18, 80.0 / 108}, 4, lines.Signal)
+	assertValues(t, []float64{0, 1.0 / 18, 7.0 / 108}, 4, lines.Histogram)
+}
+
+func Test_Bollinger(t *testing.T) {
+	bands := Bollinger(prices(1, 3, 1, 3), 2, 2)
+
+	assertValues(t, []float64{2, 2, 2}, 2, bands.Middle)
+	assertValues(t, []float64{4, 4, 4}, 2, bands.Upper)
+	assertValues(t, []float64{0, 0, 0}, 2, bands.Lower)
+}
+
+func Test_ROC(t *testing.T) {
+	assertValues(t, []float64{100, 50}, 3, ROC(prices(1, 2, 2, 3), 2))
+	assertValues(t, []float64{50}, 4, ROC(prices(0, 2, 2, 3), 2))
+}
+
+func Test_ATR(t *testing.T) {
+	bars := entity.BarList{
+		{Time: day(3), Open: 11, High: 14, Low: 11, Close: 13},
+		{Time: day(1), Open: 10, High: 12, Low: 9, Close: 11},
+		{Time: day(2), Open: 11, High: 11, Low: 8, Close: 10},
+	}
+
+	// True ranges are 3, 3 and 4, the last one from the previous close.
+	assertValues(t, []float64{3, 3.5}, 2, ATR(bars, 2))
+}
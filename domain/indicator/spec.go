@@ -0,0 +1,198 @@
+package indicator
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"org.alex859/stockprices/domain/entity"
+)
+
+type (
+	// Spec names an indicator and its parameters, e.g. "sma(20)" or "macd(12,26,9)".
+	Spec struct {
+		Name   string
+		Params []float64
+	}
+
+	// Lines are the series computed by an indicator, by name. Indicators with a single series name it "value".
+	Lines map[string]entity.PriceList
+
+	// A known indicator: its default parameters, how many of them are periods, and how to compute it.
+	definition struct {
+		defaults []float64
+		periods  int
+		validate func(params []float64) error
+		compute  func(history entity.PriceHistory, params []float64) (Lines, bool)
+	}
+)
+
+var specPattern = regexp.MustCompile(`^\s*([a-z]+)\s*(?:\(([^()]*)\))?\s*$`)
+
+var definitions = map[string]definition{
+	"sma": {defaults: []float64{20}, periods: 1, compute: single(SMA)},
+	"ema": {defaults: []float64{20}, periods: 1, compute: single(EMA)},
+	"wma": {defaults: []float64{20}, periods: 1, compute: single(WMA)},
+	"rsi": {defaults: []float64{14}, periods: 1, compute: single(RSI)},
+	"roc": {defaults: []float64{10}, periods: 1, compute: single(ROC)},
+	"macd": {
+		defaults: []float64{12, 26, 9},
+		periods:  3,
+		validate: func(params []float64) error {
+			if params[0] >= params[1] {
+				return errors.New("the fast period must be shorter than the slow one")
+			}
+			return nil
+		},
+		compute: func(history entity.PriceHistory, params []float64) (Lines, bool) {
+			lines := MACD(closes(history), int(params[0]), int(params[1]), int(params[2]))
+			return Lines{"macd": lines.MACD, "signal": lines.Signal, "histogram": lines.Histogram}, true
+		},
+	},
+	"bb": {
+		defaults: []float64{20, 2},
+		periods:  1,
+		validate: func(params []float64) error {
+			if params[1] <= 0 {
+				return errors.New("the number of standard deviations must be positive")
+			}
+			return nil
+		},
+		compute: func(history entity.PriceHistory, params []float64) (Lines, bool) {
+			bands := Bollinger(closes(history), int(params[0]), params[1])
+			return Lines{"middle": bands.Middle, "upper": bands.Upper, "lower": bands.Lower}, true
+		},
+	},
+	"atr": {
+		defaults: []float64{14},
+		periods:  1,
+		compute: func(history entity.PriceHistory, params []float64) (Lines, bool) {
+			if len(history.Bars) == 0 || history.SyntheticBars {
+				return nil, false
+			}
+			return Lines{"value": ATR(history.Bars, int(params[0]))}, true
+		},
+	},
+}
+
+// ParseSpecs reads a comma separated list of indicators like "sma(20),rsi,macd(12,26,9)".
+// Known indicators are sma, ema, wma, rsi, roc, macd, bb (Bollinger Bands, period and standard deviations) and atr.
+// Missing parameters take the usual defaults, e.g. 14 periods for rsi.
+func ParseSpecs(str string) ([]Spec, error) {
+	var result []Spec
+	for _, part := range splitSpecs(str) {
+		spec, err := ParseSpec(part)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, spec)
+	}
+	if len(result) == 0 {
+		return nil, errors.New("no indicators")
+	}
+	return result, nil
+}
+
+// ParseSpec reads a single indicator like "sma(20)" or "rsi".
+func ParseSpec(str string) (Spec, error) {
+	match := specPattern.FindStringSubmatch(strings.ToLower(str))
+	if match == nil {
+		return Spec{}, errors.Errorf("invalid indicator %q", str)
+	}
+	definition, ok := definitions[match[1]]
+	if !ok {
+		return Spec{}, errors.Errorf("unknown indicator %q", match[1])
+	}
+
+	params := append([]float64{}, definition.defaults...)
+	if strings.TrimSpace(match[2]) != "" {
+		values := strings.Split(match[2], ",")
+		if len(values) > len(params) {
+			return Spec{}, errors.Errorf("indicator %s takes at most %d parameters", match[1], len(params))
+		}
+		for i, value := range values {
+			param, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || math.IsNaN(param) || math.IsInf(param, 0) {
+				return Spec{}, errors.Errorf("invalid parameter %q of indicator %s", value, match[1])
+			}
+			params[i] = param
+		}
+	}
+
+	for _, period := range params[:definition.periods] {
+		if period < 1 || period != math.Trunc(period) {
+			return Spec{}, errors.Errorf("periods of indicator %s must be positive integers", match[1])
+		}
+	}
+	if definition.validate != nil {
+		if err := definition.validate(params); err != nil {
+			return Spec{}, errors.Wrapf(err, "invalid indicator %s", match[1])
+		}
+	}
+	return Spec{Name: match[1], Params: params}, nil
+}
+
+// String returns the spec with all its parameters, e.g. "bb(20,2)".
+func (spec Spec) String() string {
+	params := make([]string, len(spec.Params))
+	for i, param := range spec.Params {
+		params[i] = strconv.FormatFloat(param, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%s(%s)", spec.Name, strings.Join(params, ","))
+}
+
+// Compute computes the indicator over the close prices of the history, or its bars for atr.
+// ok is false when the history lacks what the indicator needs, i.e. atr of a history without bars or with synthetic ones.
+func (spec Spec) Compute(history entity.PriceHistory) (lines Lines, ok bool) {
+	return definitions[spec.Name].compute(history, spec.Params)
+}
+
+// ComputeAll computes the indicators over the history, by spec. Indicators the history lacks data for are left out.
+func ComputeAll(specs []Spec, history entity.PriceHistory) map[string]Lines {
+	result := make(map[string]Lines, len(specs))
+	for _, spec := range specs {
+		if lines, ok := spec.Compute(history); ok {
+			result[spec.String()] = lines
+		}
+	}
+	return result
+}
+
+// closes returns the close prices of the history, taken from its bars when it has no prices.
+func closes(history entity.PriceHistory) entity.PriceList {
+	if len(history.Prices) == 0 {
+		return history.Bars.Closes()
+	}
+	return history.Prices
+}
+
+// single adapts an indicator with one period and one series.
+func single(indicator func(prices entity.PriceList, period int) entity.PriceList) func(entity.PriceHistory, []float64) (Lines, bool) {
+	return func(history entity.PriceHistory, params []float64) (Lines, bool) {
+		return Lines{"value": indicator(closes(history), int(params[0]))}, true
+	}
+}
+
+// splitSpecs splits a list of indicators on the commas outside of parentheses.
+func splitSpecs(str string) []string {
+	var result []string
+	depth, start := 0, 0
+	for i, r := range str {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			result = append(result, str[start:i])
+			start = i + 1
+		}
+	}
+	if strings.TrimSpace(str[start:]) != "" || len(result) > 0 {
+		result = append(result, str[start:])
+	}
+	return result
+}
@@ -0,0 +1,46 @@
+package indicator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"org.alex859/stockprices/domain/entity"
+)
+
+func Test_ParseSpecs(t *testing.T) {
+	specs, err := ParseSpecs("sma(5), RSI,macd(3,6,2),bb(10, 1.5)")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Spec{
+			{Name: "sma", Params: []float64{5}},
+			{Name: "rsi", Params: []float64{14}},
+			{Name: "macd", Params: []float64{3, 6, 2}},
+			{Name: "bb", Params: []float64{10, 1.5}},
+		}, specs)
+		assert.Equal(t, "bb(10,1.5)", specs[3].String())
+	}
+}
+
+func Test_ParseSpecs_WHEN_Invalid_THEN_ReturnError(t *testing.T) {
+	invalid := []string{"", "sma,", "foo(3)", "sma(0)", "sma(2.5)", "sma(x)", "sma(2,3)", "macd(26,12,9)", "bb(20,0)", "bb(20,inf)", "bb(20,NaN)", "sma(inf)", "sma(3"}
+	for _, str := range invalid {
+		_, err := ParseSpecs(str)
+		assert.Error(t, err, str)
+	}
+}
+
+func Test_ComputeAll(t *testing.T) {
+	specs, _ := ParseSpecs("sma(2),atr(2),bb(2)")
+	history := entity.PriceHistory{Prices: prices(1, 3, 1)}
+
+	result := ComputeAll(specs, history)
+
+	assert.Len(t, result, 2, "no atr without bars")
+	assertValues(t, []float64{2, 2}, 2, result["sma(2)"]["value"])
+	assertValues(t, []float64{4, 4}, 2, result["bb(2,2)"]["upper"])
+
+	assert.NotContains(t, ComputeAll(specs, history.Resample(entity.Resolution{Count: 1, Unit: entity.Day}, time.UTC, entity.LastPrice)), "atr(2)", "no atr with synthetic bars")
+	history.Bars = history.Prices.Bars()
+	assertValues(t, []float64{1, 1.5}, 2, ComputeAll(specs, history)["atr(2)"]["value"])
+}
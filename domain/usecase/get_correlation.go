@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"org.alex859/stockprices/domain/analytics"
+	"org.alex859/stockprices/domain/entity"
+)
+
+type getCorrelationUseCase struct {
+	historicalPrices GetHistoricalPricesUseCase
+}
+
+var dailyAlignment = entity.AlignOptions{
+	Resolution: &entity.Resolution{Count: 1, Unit: entity.Day},
+	Location:   time.UTC,
+	Axis:       entity.IntersectionAxis,
+}
+
+// NewGetCorrelationUseCase creates a use case correlating the prices given by historicalPrices,
+// whatever provider it uses.
+func NewGetCorrelationUseCase(historicalPrices GetHistoricalPricesUseCase) *getCorrelationUseCase {
+	return &getCorrelationUseCase{historicalPrices: historicalPrices}
+}
+
+func (useCase *getCorrelationUseCase) GetCorrelation(ctx context.Context, tickers []entity.Ticker, interval entity.DateInterval) (CorrelationResult, error) {
+	prices, err := useCase.historicalPrices.GetHistoricalPrices(ctx, tickers, interval)
+	result := CorrelationResult{Tickers: []string{}, Errors: prices.Errors}
+	if err != nil {
+		return result, err
+	}
+
+	// Closes of the same UTC date are taken as the same day, which holds for the closes of most exchanges.
+	aligned := entity.Align(prices.Prices, dailyAlignment)
+	for ticker := range aligned.Prices {
+		result.Tickers = append(result.Tickers, ticker)
+	}
+	sort.Strings(result.Tickers)
+
+	returns := commonReturns(result.Tickers, aligned)
+	if len(returns) > 0 {
+		result.Observations = len(returns[0])
+	}
+	result.Pearson = analytics.NewMatrix(returns, analytics.Pearson)
+	result.Spearman = analytics.NewMatrix(returns, analytics.Spearman)
+	result.Covariance = analytics.NewMatrix(returns, analytics.Covariance)
+	return result, nil
+}
+
+// commonReturns returns the log returns between consecutive times of the aligned prices, in the order of the tickers.
+// Times where a ticker has no positive price, before or after, are left out for all the tickers.
+func commonReturns(tickers []string, aligned entity.AlignedPrices) [][]float64 {
+	result := make([][]float64, len(tickers))
+	for i := 1; i < len(aligned.Times); i++ {
+		valid := true
+		for _, ticker := range tickers {
+			previous, current := aligned.Prices[ticker][i-1], aligned.Prices[ticker][i]
+			valid = valid && previous != nil && current != nil && *previous > 0 && *current > 0
+		}
+		if !valid {
+			continue
+		}
+		for j, ticker := range tickers {
+			result[j] = append(result[j], math.Log(*aligned.Prices[ticker][i] / *aligned.Prices[ticker][i-1]))
+		}
+	}
+	return result
+}
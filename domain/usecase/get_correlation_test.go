@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase/mocks"
+)
+
+func Test_GetCorrelation_THEN_UseCommonDates(t *testing.T) {
+	priceProvider := &mocks.HistoricalPricesProvider{}
+	interval, _ := entity.NewDateInterval(from, to)
+	sdry := dailyHistory(tickerInfoSdry, 100, 90, 1, 99, 108.9)
+	// The third day is missing, its price above would break the correlation if not left out.
+	sdry.Prices = append(sdry.Prices[:2], sdry.Prices[3:]...)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, tickerInfoAnp.Ticker, interval).Return(dailyHistory(tickerInfoAnp, 10, 9, 50, 9.9, 10.89), nil)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, tickerInfoSdry.Ticker, interval).Return(sdry, nil)
+	useCase := NewGetCorrelationUseCase(NewGetHistoricalPricesUseCase(priceProvider, 1, 0))
+
+	result, err := useCase.GetCorrelation(context.Background(), []entity.Ticker{tickerInfoSdry.Ticker, tickerInfoAnp.Ticker}, interval)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"LON:ANP", "LON:SDRY"}, result.Tickers)
+		assert.Equal(t, 3, result.Observations)
+		assert.InDelta(t, 1, *result.Pearson[0][1], 1e-9)
+		assert.InDelta(t, 1, *result.Spearman[1][0], 1e-9)
+		assert.InDelta(t, *result.Covariance[0][0], *result.Covariance[0][1], 1e-9)
+	}
+}
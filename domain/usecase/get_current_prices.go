@@ -1,35 +1,44 @@
 package usecase
 
 import (
+	"context"
 	"org.alex859/stockprices/domain/entity"
 	"log"
-	"errors"
+	"github.com/pkg/errors"
+	"time"
+	"strings"
 )
 
 type getCurrentPricesUseCase struct {
 	priceProvider CurrentPriceProvider
 	numWorkers    int
+	tickerTimeout time.Duration
 }
-func NewGetCurrentPricesUseCase(dataProvider CurrentPriceProvider, numWorkers int) *getCurrentPricesUseCase {
-	return &getCurrentPricesUseCase{priceProvider: dataProvider, numWorkers:numWorkers}
+
+// NewGetCurrentPricesUseCase creates a use case querying the provider with numWorkers concurrent workers.
+// Each ticker is given at most tickerTimeout to answer, zero meaning no limit other than the one of the context.
+func NewGetCurrentPricesUseCase(dataProvider CurrentPriceProvider, numWorkers int, tickerTimeout time.Duration) *getCurrentPricesUseCase {
+	return &getCurrentPricesUseCase{priceProvider: dataProvider, numWorkers:numWorkers, tickerTimeout: tickerTimeout}
 }
 
 type currentPricesResultErrorChannel struct {
+	ticker entity.Ticker
 	result entity.CurrentPrice
 	err error
 }
 
-func (useCase *getCurrentPricesUseCase) GetCurrentPrices(tickers []entity.Ticker) (map[string]entity.CurrentPrice, error) {
+func (useCase *getCurrentPricesUseCase) GetCurrentPrices(ctx context.Context, tickers []entity.Ticker) (CurrentPricesResult, error) {
+	tickers = uniqueTickers(tickers)
 	n := len(tickers)
 	if n == 0 {
-		return map[string]entity.CurrentPrice{}, nil
+		return CurrentPricesResult{Prices: map[string]entity.CurrentPrice{}, Errors: map[string]TickerError{}}, nil
 	}
 
 	resultsChannel := make(chan currentPricesResultErrorChannel, n)
 	tickersChannel := make(chan entity.Ticker, n)
 
 	for w := 1; w <= useCase.numWorkers; w++ {
-		go currentPriceProviderWorker(useCase.priceProvider, tickersChannel, resultsChannel)
+		go useCase.currentPriceProviderWorker(ctx, tickersChannel, resultsChannel)
 	}
 
 	for _, ticker := range tickers {
@@ -37,27 +46,106 @@ func (useCase *getCurrentPricesUseCase) GetCurrentPrices(tickers []entity.Ticker
 	}
 	close(tickersChannel)
 
-	result := map[string]entity.CurrentPrice{}
+	result := CurrentPricesResult{Prices: map[string]entity.CurrentPrice{}, Errors: map[string]TickerError{}}
+	pending := pendingTickers(tickers)
+	var failures []error
 	for i := 0; i < n; i++ {
-		r := <-resultsChannel
-		if r.err == nil {
-			result[r.result.Ticker.String()] = r.result
+		select {
+		case r := <-resultsChannel:
+			delete(pending, r.ticker.String())
+			if r.err == nil {
+				result.Prices[r.result.Ticker.String()] = r.result
+				continue
+			}
+			result.Errors[r.ticker.String()] = NewTickerError(r.err)
+			failures = append(failures, r.err)
+		case <-ctx.Done():
+			i = n
 		}
 	}
+	for key := range pending {
+		result.Errors[key] = NewTickerError(ctx.Err())
+	}
 
-	if len(result) == 0 {
-		return nil, errors.New("unable to fetch stock prices")
+	if len(result.Prices) == 0 {
+		return result, noPricesError(ctx, failures)
 	}
 
 	return result, nil
 }
 
-func currentPriceProviderWorker(priceProvider CurrentPriceProvider, tickersChannel <-chan entity.Ticker, ch chan<- currentPricesResultErrorChannel) {
+func (useCase *getCurrentPricesUseCase) currentPriceProviderWorker(ctx context.Context, tickersChannel <-chan entity.Ticker, ch chan<- currentPricesResultErrorChannel) {
 	for ticker := range tickersChannel {
-		history, err := priceProvider.GetCurrentPrice(ticker)
+		if ctx.Err() != nil {
+			ch <- currentPricesResultErrorChannel{ticker: ticker, err: ctx.Err()}
+			continue
+		}
+
+		tickerCtx, cancel := withTickerTimeout(ctx, useCase.tickerTimeout)
+		history, err := useCase.priceProvider.GetCurrentPrice(tickerCtx, ticker)
+		cancel()
 		if err != nil {
 			log.Printf("An error occured while fetching prices for ticker: %s. Error: %+v", ticker.String(), err)
 		}
-		ch <- currentPricesResultErrorChannel{result:history, err:err}
+		ch <- currentPricesResultErrorChannel{ticker: ticker, result:history, err:err}
+	}
+}
+
+// withTickerTimeout derives the context used to query a single ticker.
+func withTickerTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// noPricesError tells why not a single price could be fetched.
+// An upstream refusing our requests is reported as such, so that callers can ask their clients to come back later.
+// When all the tickers failed for the same kind of reason, the first failure is reported, otherwise an upstream failure
+// listing them all.
+func noPricesError(ctx context.Context, failures []error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	for _, failure := range failures {
+		if throttled, ok := errors.Cause(failure).(entity.ErrUpstreamThrottled); ok {
+			return throttled
+		}
+	}
+	if len(failures) > 0 {
+		sameKind := true
+		for _, failure := range failures[1:] {
+			sameKind = sameKind && entity.KindOf(failure) == entity.KindOf(failures[0])
+		}
+		if sameKind {
+			return failures[0]
+		}
+	}
+	reasons := make([]string, len(failures))
+	for i, failure := range failures {
+		reasons[i] = failure.Error()
+	}
+	return entity.NewErrUpstreamFailure("price sources", strings.Join(reasons, "; "))
+}
+
+// pendingTickers indexes the tickers a use case is waiting for by their string form.
+func pendingTickers(tickers []entity.Ticker) map[string]entity.Ticker {
+	result := make(map[string]entity.Ticker, len(tickers))
+	for _, ticker := range tickers {
+		result[ticker.String()] = ticker
 	}
-}
\ No newline at end of file
+	return result
+}
+
+// uniqueTickers removes the repeated tickers, so that each of them is fetched once.
+func uniqueTickers(tickers []entity.Ticker) []entity.Ticker {
+	seen := make(map[entity.Ticker]bool, len(tickers))
+	result := make([]entity.Ticker, 0, len(tickers))
+	for _, ticker := range tickers {
+		if !seen[ticker] {
+			seen[ticker] = true
+			result = append(result, ticker)
+		}
+	}
+	return result
+}
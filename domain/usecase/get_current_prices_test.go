@@ -1,20 +1,23 @@
 package usecase
 
 import (
+	"context"
 	"testing"
 	"org.alex859/stockprices/domain/entity"
 	"errors"
+	"time"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"org.alex859/stockprices/domain/usecase/mocks"
 )
 
 func Test_GetCurrentPrices_WHEN_NoTickers_THEN_ReturnEmptyMap(t *testing.T) {
 	priceProvider := &mocks.CurrentPriceProvider{}
-	useCase := NewGetCurrentPricesUseCase(priceProvider, 1)
-	result, err := useCase.GetCurrentPrices([]entity.Ticker{})
+	useCase := NewGetCurrentPricesUseCase(priceProvider, 1, 0)
+	result, err := useCase.GetCurrentPrices(context.Background(), []entity.Ticker{})
 
 	if assert.NoError(t, err) {
-		assert.Equal(t, map[string]entity.CurrentPrice{}, result)
+		assert.Equal(t, map[string]entity.CurrentPrice{}, result.Prices)
 	}
 }
 
@@ -22,9 +25,9 @@ func Test_GetCurrentPrices_WHEN_ErrorQueryingOneTicker_THEN_ReturnError(t *testi
 	priceProvider := &mocks.CurrentPriceProvider{}
 	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
 	var providedHistory entity.CurrentPrice
-	priceProvider.On("GetCurrentPrice", ticker1).Return(providedHistory, errors.New("an error occurred"))
-	useCase := NewGetCurrentPricesUseCase(priceProvider, 1)
-	_, err := useCase.GetCurrentPrices([]entity.Ticker{ticker1})
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker1).Return(providedHistory, errors.New("an error occurred"))
+	useCase := NewGetCurrentPricesUseCase(priceProvider, 1, 0)
+	_, err := useCase.GetCurrentPrices(context.Background(), []entity.Ticker{ticker1})
 
 	assert.Error(t, err)
 }
@@ -33,12 +36,12 @@ func Test_GetCurrentPrices_WHEN_OKQueryingOneTicker_THEN_ReturnCorrect(t *testin
 	priceProvider := &mocks.CurrentPriceProvider{}
 	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
 
-	priceProvider.On("GetCurrentPrice", ticker1).Return(entity.CurrentPrice{TickerInfo:tickerInfoAnp}, nil)
-	useCase := NewGetCurrentPricesUseCase(priceProvider, 1)
-	result, err := useCase.GetCurrentPrices([]entity.Ticker{ticker1})
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker1).Return(entity.CurrentPrice{TickerInfo:tickerInfoAnp}, nil)
+	useCase := NewGetCurrentPricesUseCase(priceProvider, 1, 0)
+	result, err := useCase.GetCurrentPrices(context.Background(), []entity.Ticker{ticker1})
 
 	if assert.NoError(t, err) {
-		assert.Equal(t, map[string]entity.CurrentPrice{"LON:ANP": {TickerInfo: tickerInfoAnp}}, result)
+		assert.Equal(t, map[string]entity.CurrentPrice{"LON:ANP": {TickerInfo: tickerInfoAnp}}, result.Prices)
 	}
 
 }
@@ -47,16 +50,16 @@ func Test_GetCurrentPrices_WHEN_OKQueryingMultipleTicker_THEN_ReturnCorrect(t *t
 	priceProvider := &mocks.CurrentPriceProvider{}
 	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
 	ticker2 := entity.Ticker{Symbol:"SDRY", Market:"LON"}
-	priceProvider.On("GetCurrentPrice", ticker1).Return(entity.CurrentPrice{TickerInfo: tickerInfoAnp}, nil)
-	priceProvider.On("GetCurrentPrice", ticker2).Return(entity.CurrentPrice{TickerInfo: tickerInfoSdry}, nil)
-	useCase := NewGetCurrentPricesUseCase(priceProvider, 1)
-	result, err := useCase.GetCurrentPrices([]entity.Ticker{ticker1, ticker2})
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker1).Return(entity.CurrentPrice{TickerInfo: tickerInfoAnp}, nil)
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker2).Return(entity.CurrentPrice{TickerInfo: tickerInfoSdry}, nil)
+	useCase := NewGetCurrentPricesUseCase(priceProvider, 1, 0)
+	result, err := useCase.GetCurrentPrices(context.Background(), []entity.Ticker{ticker1, ticker2})
 
 	if assert.NoError(t, err) {
 		assert.Equal(t, map[string]entity.CurrentPrice{
 			"LON:ANP": {TickerInfo: tickerInfoAnp},
 			"LON:SDRY": {TickerInfo: tickerInfoSdry},
-		}, result)
+		}, result.Prices)
 	}
 
 }
@@ -65,16 +68,16 @@ func Test_GetCurrentPrices_WHEN_OKQueryingMultipleTickerAndMultipleWorkers_THEN_
 	priceProvider := &mocks.CurrentPriceProvider{}
 	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
 	ticker2 := entity.Ticker{Symbol:"SDRY", Market:"LON"}
-	priceProvider.On("GetCurrentPrice", ticker1).Return(entity.CurrentPrice{TickerInfo: tickerInfoAnp}, nil)
-	priceProvider.On("GetCurrentPrice", ticker2).Return(entity.CurrentPrice{TickerInfo: tickerInfoSdry}, nil)
-	useCase := NewGetCurrentPricesUseCase(priceProvider, 2)
-	result, err := useCase.GetCurrentPrices([]entity.Ticker{ticker1, ticker2})
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker1).Return(entity.CurrentPrice{TickerInfo: tickerInfoAnp}, nil)
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker2).Return(entity.CurrentPrice{TickerInfo: tickerInfoSdry}, nil)
+	useCase := NewGetCurrentPricesUseCase(priceProvider, 2, 0)
+	result, err := useCase.GetCurrentPrices(context.Background(), []entity.Ticker{ticker1, ticker2})
 
 	if assert.NoError(t, err) {
 		assert.Equal(t, map[string]entity.CurrentPrice{
 			"LON:ANP": {TickerInfo: tickerInfoAnp},
 			"LON:SDRY": {TickerInfo: tickerInfoSdry},
-		}, result)
+		}, result.Prices)
 	}
 
 }
@@ -84,10 +87,10 @@ func Test_GetCurrentPrices_WHEN_ErrorQueryingAllMultipleTicker_THEN_ReturnError(
 	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
 	ticker2 := entity.Ticker{Symbol:"SDRY", Market:"LON"}
 	var noResult entity.CurrentPrice
-	priceProvider.On("GetCurrentPrice", ticker1).Return(noResult, errors.New("an error occurred"))
-	priceProvider.On("GetCurrentPrice", ticker2).Return(noResult, errors.New("an error occurred"))
-	useCase := NewGetCurrentPricesUseCase(priceProvider, 1)
-	_, err := useCase.GetCurrentPrices([]entity.Ticker{ticker1, ticker2})
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker1).Return(noResult, errors.New("an error occurred"))
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker2).Return(noResult, errors.New("an error occurred"))
+	useCase := NewGetCurrentPricesUseCase(priceProvider, 1, 0)
+	_, err := useCase.GetCurrentPrices(context.Background(), []entity.Ticker{ticker1, ticker2})
 
 	assert.Error(t, err)
 }
@@ -97,15 +100,107 @@ func Test_GetCurrentPrices_WHEN_ErrorQueryingOneMultipleTicker_THEN_ReturnOneCor
 	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
 	ticker2 := entity.Ticker{Symbol:"SDRY", Market:"LON"}
 	var noResult entity.CurrentPrice
-	priceProvider.On("GetCurrentPrice", ticker1).Return(entity.CurrentPrice{TickerInfo: tickerInfoAnp}, nil)
-	priceProvider.On("GetCurrentPrice", ticker2).Return(noResult, errors.New("an error occurred"))
-	useCase := NewGetCurrentPricesUseCase(priceProvider, 1)
-	result, err := useCase.GetCurrentPrices([]entity.Ticker{ticker1, ticker2})
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker1).Return(entity.CurrentPrice{TickerInfo: tickerInfoAnp}, nil)
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker2).Return(noResult, errors.New("an error occurred"))
+	useCase := NewGetCurrentPricesUseCase(priceProvider, 1, 0)
+	result, err := useCase.GetCurrentPrices(context.Background(), []entity.Ticker{ticker1, ticker2})
 
 	if assert.NoError(t, err) {
 		assert.Equal(t, map[string]entity.CurrentPrice{
 			"LON:ANP": {TickerInfo: tickerInfoAnp},
-		}, result)
+		}, result.Prices)
+		assert.Equal(t, map[string]TickerError{
+			"LON:SDRY": {Kind: entity.UpstreamFailure, Message: "an error occurred"},
+		}, result.Errors)
 	}
 
-}
\ No newline at end of file
+}
+func Test_GetCurrentPrices_WHEN_OneTickerTimesOut_THEN_ReturnOthers(t *testing.T) {
+	priceProvider := &mocks.CurrentPriceProvider{}
+	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
+	ticker2 := entity.Ticker{Symbol:"SDRY", Market:"LON"}
+	waitForDeadline := func(ctx context.Context, ticker entity.Ticker) entity.CurrentPrice {
+		<-ctx.Done()
+		return entity.CurrentPrice{}
+	}
+	contextError := func(ctx context.Context, ticker entity.Ticker) error {
+		return ctx.Err()
+	}
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker1).Return(waitForDeadline, contextError)
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker2).Return(entity.CurrentPrice{TickerInfo: tickerInfoSdry}, nil)
+	useCase := NewGetCurrentPricesUseCase(priceProvider, 2, 10*time.Millisecond)
+	result, err := useCase.GetCurrentPrices(context.Background(), []entity.Ticker{ticker1, ticker2})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]entity.CurrentPrice{
+			"LON:SDRY": {TickerInfo: tickerInfoSdry},
+		}, result.Prices)
+		assert.Equal(t, entity.Timeout, result.Errors["LON:ANP"].Kind)
+	}
+}
+
+func Test_GetCurrentPrices_WHEN_ContextDone_THEN_ReturnContextError(t *testing.T) {
+	priceProvider := &mocks.CurrentPriceProvider{}
+	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	useCase := NewGetCurrentPricesUseCase(priceProvider, 1, 0)
+	_, err := useCase.GetCurrentPrices(ctx, []entity.Ticker{ticker1})
+
+	assert.Equal(t, context.Canceled, err)
+	priceProvider.AssertNotCalled(t, "GetCurrentPrice", mock.Anything, mock.Anything)
+}
+
+func Test_GetCurrentPrices_WHEN_AllTickersThrottled_THEN_ReturnThrottledError(t *testing.T) {
+	priceProvider := &mocks.CurrentPriceProvider{}
+	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
+	ticker2 := entity.Ticker{Symbol:"SDRY", Market:"LON"}
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker1).Return(entity.CurrentPrice{}, errors.New("an error occurred"))
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker2).Return(entity.CurrentPrice{}, entity.NewErrUpstreamThrottled("google finance", "429", time.Second))
+	useCase := NewGetCurrentPricesUseCase(priceProvider, 1, 0)
+	_, err := useCase.GetCurrentPrices(context.Background(), []entity.Ticker{ticker1, ticker2})
+
+	if assert.IsType(t, entity.ErrUpstreamThrottled{}, err) {
+		assert.Equal(t, time.Second, err.(entity.ErrUpstreamThrottled).RetryAfter)
+	}
+}
+
+func Test_GetCurrentPrices_WHEN_AllTickersFailDifferently_THEN_ReturnUpstreamFailure(t *testing.T) {
+	priceProvider := &mocks.CurrentPriceProvider{}
+	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
+	ticker2 := entity.Ticker{Symbol:"SDRY", Market:"LON"}
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker1).Return(entity.CurrentPrice{}, entity.NewErrUpstreamFailure("google finance", "503"))
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker2).Return(entity.CurrentPrice{}, entity.NewErrMalformedData("google finance", "no rows"))
+	useCase := NewGetCurrentPricesUseCase(priceProvider, 1, 0)
+	_, err := useCase.GetCurrentPrices(context.Background(), []entity.Ticker{ticker1, ticker2})
+
+	assert.IsType(t, entity.ErrUpstreamFailure{}, err)
+}
+
+func Test_GetCurrentPrices_WHEN_TickerNotFound_THEN_ReportItsKind(t *testing.T) {
+	priceProvider := &mocks.CurrentPriceProvider{}
+	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
+	ticker2 := entity.Ticker{Symbol:"XXX", Market:"LON"}
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker1).Return(entity.CurrentPrice{TickerInfo: tickerInfoAnp}, nil)
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker2).Return(entity.CurrentPrice{}, entity.NewErrNothingFound(ticker2))
+	useCase := NewGetCurrentPricesUseCase(priceProvider, 1, 0)
+	result, err := useCase.GetCurrentPrices(context.Background(), []entity.Ticker{ticker1, ticker2})
+
+	if assert.NoError(t, err) {
+		assert.Len(t, result.Prices, 1)
+		assert.Equal(t, entity.NotFound, result.Errors["LON:XXX"].Kind)
+	}
+}
+
+func Test_GetCurrentPrices_WHEN_RepeatedTicker_THEN_FetchItOnce(t *testing.T) {
+	priceProvider := &mocks.CurrentPriceProvider{}
+	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
+	priceProvider.On("GetCurrentPrice", mock.Anything, ticker1).Return(entity.CurrentPrice{TickerInfo: tickerInfoAnp}, nil)
+	useCase := NewGetCurrentPricesUseCase(priceProvider, 2, 0)
+	result, err := useCase.GetCurrentPrices(context.Background(), []entity.Ticker{ticker1, ticker1, ticker1})
+
+	if assert.NoError(t, err) {
+		assert.Len(t, result.Prices, 1)
+	}
+	priceProvider.AssertNumberOfCalls(t, "GetCurrentPrice", 1)
+}
@@ -1,35 +1,42 @@
 package usecase
 
 import (
+	"context"
 	"org.alex859/stockprices/domain/entity"
 	"log"
-	"github.com/pkg/errors"
+	"time"
 )
 
 type getHistoricalPricesUseCase struct {
 	priceProvider HistoricalPricesProvider
 	numWorkers    int
+	tickerTimeout time.Duration
 }
-func NewGetHistoricalPricesUseCase(dataProvider HistoricalPricesProvider, numWorkers int) *getHistoricalPricesUseCase {
-	return &getHistoricalPricesUseCase{priceProvider: dataProvider, numWorkers:numWorkers}
+
+// NewGetHistoricalPricesUseCase creates a use case querying the provider with numWorkers concurrent workers.
+// Each ticker is given at most tickerTimeout to answer, zero meaning no limit other than the one of the context.
+func NewGetHistoricalPricesUseCase(dataProvider HistoricalPricesProvider, numWorkers int, tickerTimeout time.Duration) *getHistoricalPricesUseCase {
+	return &getHistoricalPricesUseCase{priceProvider: dataProvider, numWorkers:numWorkers, tickerTimeout: tickerTimeout}
 }
 
 type historicalPricesResultErrorChannel struct {
+	ticker entity.Ticker
 	result entity.PriceHistory
 	err error
 }
 
-func (useCase *getHistoricalPricesUseCase) GetHistoricalPrices(tickers []entity.Ticker, interval entity.DateInterval) (map[string]entity.PriceHistory, error) {
+func (useCase *getHistoricalPricesUseCase) GetHistoricalPrices(ctx context.Context, tickers []entity.Ticker, interval entity.DateInterval) (HistoricalPricesResult, error) {
+	tickers = uniqueTickers(tickers)
 	n := len(tickers)
 	if n == 0 {
-		return map[string]entity.PriceHistory{}, nil
+		return HistoricalPricesResult{Prices: map[string]entity.PriceHistory{}, Errors: map[string]TickerError{}}, nil
 	}
 
 	resultsChannel := make(chan historicalPricesResultErrorChannel, n)
 	tickersChannel := make(chan entity.Ticker, n)
 
 	for w := 1; w <= useCase.numWorkers; w++ {
-		go historicalPricesProviderWorker(useCase.priceProvider, tickersChannel, resultsChannel, interval)
+		go useCase.historicalPricesProviderWorker(ctx, tickersChannel, resultsChannel, interval)
 	}
 
 	for _, ticker := range tickers {
@@ -37,27 +44,47 @@ func (useCase *getHistoricalPricesUseCase) GetHistoricalPrices(tickers []entity.
 	}
 	close(tickersChannel)
 
-	result := map[string]entity.PriceHistory{}
+	result := HistoricalPricesResult{Prices: map[string]entity.PriceHistory{}, Errors: map[string]TickerError{}}
+	pending := pendingTickers(tickers)
+	var failures []error
 	for i := 0; i < n; i++ {
-		r := <-resultsChannel
-		if r.err == nil {
-			result[r.result.Ticker.String()] = r.result
+		select {
+		case r := <-resultsChannel:
+			delete(pending, r.ticker.String())
+			if r.err == nil {
+				result.Prices[r.result.Ticker.String()] = r.result
+				continue
+			}
+			result.Errors[r.ticker.String()] = NewTickerError(r.err)
+			failures = append(failures, r.err)
+		case <-ctx.Done():
+			i = n
 		}
 	}
+	for key := range pending {
+		result.Errors[key] = NewTickerError(ctx.Err())
+	}
 
-	if len(result) == 0 {
-		return nil, errors.New("unable to fetch stock prices")
+	if len(result.Prices) == 0 {
+		return result, noPricesError(ctx, failures)
 	}
 
 	return result, nil
 }
 
-func historicalPricesProviderWorker(priceProvider HistoricalPricesProvider, tickersChannel <-chan entity.Ticker, ch chan<- historicalPricesResultErrorChannel, interval entity.DateInterval) {
+func (useCase *getHistoricalPricesUseCase) historicalPricesProviderWorker(ctx context.Context, tickersChannel <-chan entity.Ticker, ch chan<- historicalPricesResultErrorChannel, interval entity.DateInterval) {
 	for ticker := range tickersChannel {
-		history, err := priceProvider.GetHistoricalPrices(ticker, interval)
+		if ctx.Err() != nil {
+			ch <- historicalPricesResultErrorChannel{ticker: ticker, err: ctx.Err()}
+			continue
+		}
+
+		tickerCtx, cancel := withTickerTimeout(ctx, useCase.tickerTimeout)
+		history, err := useCase.priceProvider.GetHistoricalPrices(tickerCtx, ticker, interval)
+		cancel()
 		if err != nil {
 			log.Printf("An error occured while fetching prices for ticker: %s, interval: %s. Error: %+v", ticker.String(), interval, err)
 		}
-		ch <- historicalPricesResultErrorChannel{result:history, err:err}
+		ch <- historicalPricesResultErrorChannel{ticker: ticker, result:history, err:err}
 	}
-}
\ No newline at end of file
+}
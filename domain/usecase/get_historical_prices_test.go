@@ -1,10 +1,12 @@
 package usecase
 
 import (
+	"context"
 	"testing"
 	"org.alex859/stockprices/domain/entity"
 	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"time"
 	"org.alex859/stockprices/domain/usecase/mocks"
 )
@@ -17,12 +19,12 @@ var tickerInfoSdry = entity.TickerInfo{Ticker:entity.Ticker{Symbol:"SDRY", Marke
 
 func Test_GetHistoricalPrices_WHEN_NoTickers_THEN_ReturnEmptyMap(t *testing.T) {
 	priceProvider := &mocks.HistoricalPricesProvider{}
-	useCase := NewGetHistoricalPricesUseCase(priceProvider, 1)
+	useCase := NewGetHistoricalPricesUseCase(priceProvider, 1, 0)
 	interval, err := entity.NewDateInterval(from, to)
-	result, err := useCase.GetHistoricalPrices([]entity.Ticker{}, interval)
+	result, err := useCase.GetHistoricalPrices(context.Background(), []entity.Ticker{}, interval)
 
 	if assert.NoError(t, err) {
-		assert.Equal(t, map[string]entity.PriceHistory{}, result)
+		assert.Equal(t, map[string]entity.PriceHistory{}, result.Prices)
 	}
 }
 
@@ -31,9 +33,9 @@ func Test_GetHistoricalPrices_WHEN_ErrorQueryingOneTicker_THEN_ReturnError(t *te
 	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
 	interval, err := entity.NewDateInterval(from, to)
 	var providedHistory entity.PriceHistory
-	priceProvider.On("GetHistoricalPrices", ticker1, interval).Return(providedHistory, errors.New("an error occurred"))
-	useCase := NewGetHistoricalPricesUseCase(priceProvider, 1)
-	_, err = useCase.GetHistoricalPrices([]entity.Ticker{ticker1}, interval)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, ticker1, interval).Return(providedHistory, errors.New("an error occurred"))
+	useCase := NewGetHistoricalPricesUseCase(priceProvider, 1, 0)
+	_, err = useCase.GetHistoricalPrices(context.Background(), []entity.Ticker{ticker1}, interval)
 
 	assert.Error(t, err)
 }
@@ -43,12 +45,12 @@ func Test_GetHistoricalPrices_WHEN_OKQueryingOneTicker_THEN_ReturnCorrect(t *tes
 	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
 	interval, err := entity.NewDateInterval(from, to)
 
-	priceProvider.On("GetHistoricalPrices", ticker1, interval).Return(entity.PriceHistory{TickerInfo:tickerInfoAnp}, nil)
-	useCase := NewGetHistoricalPricesUseCase(priceProvider, 1)
-	result, err := useCase.GetHistoricalPrices([]entity.Ticker{ticker1}, interval)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, ticker1, interval).Return(entity.PriceHistory{TickerInfo:tickerInfoAnp}, nil)
+	useCase := NewGetHistoricalPricesUseCase(priceProvider, 1, 0)
+	result, err := useCase.GetHistoricalPrices(context.Background(), []entity.Ticker{ticker1}, interval)
 
 	if assert.NoError(t, err) {
-		assert.Equal(t, map[string]entity.PriceHistory{"LON:ANP": {TickerInfo: tickerInfoAnp}}, result)
+		assert.Equal(t, map[string]entity.PriceHistory{"LON:ANP": {TickerInfo: tickerInfoAnp}}, result.Prices)
 	}
 
 }
@@ -58,16 +60,16 @@ func Test_GetHistoricalPrices_WHEN_OKQueryingMultipleTicker_THEN_ReturnCorrect(t
 	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
 	ticker2 := entity.Ticker{Symbol:"SDRY", Market:"LON"}
 	interval, err := entity.NewDateInterval(from, to)
-	priceProvider.On("GetHistoricalPrices", ticker1, interval).Return(entity.PriceHistory{TickerInfo: tickerInfoAnp}, nil)
-	priceProvider.On("GetHistoricalPrices", ticker2, interval).Return(entity.PriceHistory{TickerInfo: tickerInfoSdry}, nil)
-	useCase := NewGetHistoricalPricesUseCase(priceProvider, 1)
-	result, err := useCase.GetHistoricalPrices([]entity.Ticker{ticker1, ticker2}, interval)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, ticker1, interval).Return(entity.PriceHistory{TickerInfo: tickerInfoAnp}, nil)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, ticker2, interval).Return(entity.PriceHistory{TickerInfo: tickerInfoSdry}, nil)
+	useCase := NewGetHistoricalPricesUseCase(priceProvider, 1, 0)
+	result, err := useCase.GetHistoricalPrices(context.Background(), []entity.Ticker{ticker1, ticker2}, interval)
 
 	if assert.NoError(t, err) {
 		assert.Equal(t, map[string]entity.PriceHistory{
 			"LON:ANP": {TickerInfo: tickerInfoAnp},
 			"LON:SDRY": {TickerInfo: tickerInfoSdry},
-		}, result)
+		}, result.Prices)
 	}
 
 }
@@ -77,16 +79,16 @@ func Test_GetHistoricalPrices_WHEN_OKQueryingMultipleTickerAndMultipleWorkers_TH
 	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
 	ticker2 := entity.Ticker{Symbol:"SDRY", Market:"LON"}
 	interval, err := entity.NewDateInterval(from, to)
-	priceProvider.On("GetHistoricalPrices", ticker1, interval).Return(entity.PriceHistory{TickerInfo: tickerInfoAnp}, nil)
-	priceProvider.On("GetHistoricalPrices", ticker2, interval).Return(entity.PriceHistory{TickerInfo: tickerInfoSdry}, nil)
-	useCase := NewGetHistoricalPricesUseCase(priceProvider, 2)
-	result, err := useCase.GetHistoricalPrices([]entity.Ticker{ticker1, ticker2}, interval)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, ticker1, interval).Return(entity.PriceHistory{TickerInfo: tickerInfoAnp}, nil)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, ticker2, interval).Return(entity.PriceHistory{TickerInfo: tickerInfoSdry}, nil)
+	useCase := NewGetHistoricalPricesUseCase(priceProvider, 2, 0)
+	result, err := useCase.GetHistoricalPrices(context.Background(), []entity.Ticker{ticker1, ticker2}, interval)
 
 	if assert.NoError(t, err) {
 		assert.Equal(t, map[string]entity.PriceHistory{
 			"LON:ANP": {TickerInfo: tickerInfoAnp},
 			"LON:SDRY": {TickerInfo: tickerInfoSdry},
-		}, result)
+		}, result.Prices)
 	}
 
 }
@@ -97,10 +99,10 @@ func Test_GetHistoricalPrices_WHEN_ErrorQueryingAllMultipleTicker_THEN_ReturnErr
 	ticker2 := entity.Ticker{Symbol:"SDRY", Market:"LON"}
 	interval, err := entity.NewDateInterval(from, to)
 	var noResult entity.PriceHistory
-	priceProvider.On("GetHistoricalPrices", ticker1, interval).Return(noResult, errors.New("an error occurred"))
-	priceProvider.On("GetHistoricalPrices", ticker2, interval).Return(noResult, errors.New("an error occurred"))
-	useCase := NewGetHistoricalPricesUseCase(priceProvider, 1)
-	_, err = useCase.GetHistoricalPrices([]entity.Ticker{ticker1, ticker2}, interval)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, ticker1, interval).Return(noResult, errors.New("an error occurred"))
+	priceProvider.On("GetHistoricalPrices", mock.Anything, ticker2, interval).Return(noResult, errors.New("an error occurred"))
+	useCase := NewGetHistoricalPricesUseCase(priceProvider, 1, 0)
+	_, err = useCase.GetHistoricalPrices(context.Background(), []entity.Ticker{ticker1, ticker2}, interval)
 
 	assert.Error(t, err)
 }
@@ -111,15 +113,80 @@ func Test_GetHistoricalPrices_WHEN_ErrorQueryingOneMultipleTicker_THEN_ReturnOne
 	ticker2 := entity.Ticker{Symbol:"SDRY", Market:"LON"}
 	interval, err := entity.NewDateInterval(from, to)
 	var noResult entity.PriceHistory
-	priceProvider.On("GetHistoricalPrices", ticker1, interval).Return(entity.PriceHistory{TickerInfo: tickerInfoAnp}, nil)
-	priceProvider.On("GetHistoricalPrices", ticker2, interval).Return(noResult, errors.New("an error occurred"))
-	useCase := NewGetHistoricalPricesUseCase(priceProvider, 1)
-	result, err := useCase.GetHistoricalPrices([]entity.Ticker{ticker1, ticker2}, interval)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, ticker1, interval).Return(entity.PriceHistory{TickerInfo: tickerInfoAnp}, nil)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, ticker2, interval).Return(noResult, errors.New("an error occurred"))
+	useCase := NewGetHistoricalPricesUseCase(priceProvider, 1, 0)
+	result, err := useCase.GetHistoricalPrices(context.Background(), []entity.Ticker{ticker1, ticker2}, interval)
 
 	if assert.NoError(t, err) {
 		assert.Equal(t, map[string]entity.PriceHistory{
 			"LON:ANP": {TickerInfo: tickerInfoAnp},
-		}, result)
+		}, result.Prices)
+		assert.Equal(t, map[string]TickerError{
+			"LON:SDRY": {Kind: entity.UpstreamFailure, Message: "an error occurred"},
+		}, result.Errors)
 	}
 
-}
\ No newline at end of file
+}
+func Test_GetHistoricalPrices_WHEN_OneTickerTimesOut_THEN_ReturnOthers(t *testing.T) {
+	priceProvider := &mocks.HistoricalPricesProvider{}
+	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
+	ticker2 := entity.Ticker{Symbol:"SDRY", Market:"LON"}
+	interval, err := entity.NewDateInterval(from, to)
+	waitForDeadline := func(ctx context.Context, ticker entity.Ticker, interval entity.DateInterval) entity.PriceHistory {
+		<-ctx.Done()
+		return entity.PriceHistory{}
+	}
+	contextError := func(ctx context.Context, ticker entity.Ticker, interval entity.DateInterval) error {
+		return ctx.Err()
+	}
+	priceProvider.On("GetHistoricalPrices", mock.Anything, ticker1, interval).Return(waitForDeadline, contextError)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, ticker2, interval).Return(entity.PriceHistory{TickerInfo: tickerInfoSdry}, nil)
+	useCase := NewGetHistoricalPricesUseCase(priceProvider, 2, 10*time.Millisecond)
+	result, err := useCase.GetHistoricalPrices(context.Background(), []entity.Ticker{ticker1, ticker2}, interval)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]entity.PriceHistory{
+			"LON:SDRY": {TickerInfo: tickerInfoSdry},
+		}, result.Prices)
+		assert.Equal(t, entity.Timeout, result.Errors["LON:ANP"].Kind)
+	}
+}
+
+func Test_GetHistoricalPrices_WHEN_ContextDone_THEN_ReturnContextError(t *testing.T) {
+	priceProvider := &mocks.HistoricalPricesProvider{}
+	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
+	interval, err := entity.NewDateInterval(from, to)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	useCase := NewGetHistoricalPricesUseCase(priceProvider, 1, 0)
+	_, err = useCase.GetHistoricalPrices(ctx, []entity.Ticker{ticker1}, interval)
+
+	assert.Equal(t, context.Canceled, err)
+	priceProvider.AssertNotCalled(t, "GetHistoricalPrices", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_GetHistoricalPrices_WHEN_AllTickersThrottled_THEN_ReturnThrottledError(t *testing.T) {
+	priceProvider := &mocks.HistoricalPricesProvider{}
+	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
+	interval, _ := entity.NewDateInterval(time.Now().AddDate(0, 0, -7), time.Now())
+	priceProvider.On("GetHistoricalPrices", mock.Anything, ticker1, interval).Return(entity.PriceHistory{}, entity.NewErrUpstreamThrottled("google finance", "captcha", 0))
+	useCase := NewGetHistoricalPricesUseCase(priceProvider, 1, 0)
+	_, err := useCase.GetHistoricalPrices(context.Background(), []entity.Ticker{ticker1}, interval)
+
+	assert.IsType(t, entity.ErrUpstreamThrottled{}, err)
+}
+
+func Test_GetHistoricalPrices_WHEN_AllTickersNotFound_THEN_ReturnNothingFound(t *testing.T) {
+	priceProvider := &mocks.HistoricalPricesProvider{}
+	ticker1 := entity.Ticker{Symbol:"ANP", Market:"LON"}
+	ticker2 := entity.Ticker{Symbol:"XXX", Market:"LON"}
+	interval, _ := entity.NewDateInterval(from, to)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, ticker1, interval).Return(entity.PriceHistory{}, entity.NewErrNothingFound(ticker1))
+	priceProvider.On("GetHistoricalPrices", mock.Anything, ticker2, interval).Return(entity.PriceHistory{}, entity.NewErrNothingFound(ticker2))
+	useCase := NewGetHistoricalPricesUseCase(priceProvider, 1, 0)
+	result, err := useCase.GetHistoricalPrices(context.Background(), []entity.Ticker{ticker1, ticker2}, interval)
+
+	assert.IsType(t, entity.ErrNothingFound{}, err)
+	assert.Len(t, result.Errors, 2)
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"org.alex859/stockprices/domain/analytics"
+	"org.alex859/stockprices/domain/entity"
+)
+
+// lookBack is how far before the interval prices are fetched, so that holdings can be valued on its first days
+// even when their exchange was closed.
+const lookBack = 7
+
+type getPortfolioReturnsUseCase struct {
+	store            LedgerStore
+	historicalPrices GetHistoricalPricesUseCase
+}
+
+// NewGetPortfolioReturnsUseCase creates a use case replaying the ledgers of the store and valuing their holdings at
+// the prices given by historicalPrices.
+func NewGetPortfolioReturnsUseCase(store LedgerStore, historicalPrices GetHistoricalPricesUseCase) *getPortfolioReturnsUseCase {
+	return &getPortfolioReturnsUseCase{store: store, historicalPrices: historicalPrices}
+}
+
+func (useCase *getPortfolioReturnsUseCase) GetPortfolioReturns(ctx context.Context, portfolioID string, interval entity.DateInterval) (PortfolioReturnsResult, error) {
+	result := PortfolioReturnsResult{ID: portfolioID, Values: []entity.DailyValue{}, Errors: map[string]TickerError{}}
+	ledger, err := useCase.store.GetLedger(ctx, portfolioID)
+	if err != nil {
+		return result, errors.Wrapf(err, "unable to load the ledger of portfolio %s", portfolioID)
+	}
+	if len(ledger.Transactions) == 0 {
+		return result, entity.NewErrPortfolioNotFound(portfolioID)
+	}
+	result.Currency = ledger.Currency
+
+	// Tickers sold before the interval, e.g. delisted ones, need no prices.
+	days := interval.TradingDays(entity.UTCCalendar)
+	prices := HistoricalPricesResult{Prices: map[string]entity.PriceHistory{}}
+	if tickers := ledger.HeldTickers(days); len(tickers) > 0 {
+		pricesInterval, _ := entity.NewDateInterval(interval.From().AddDate(0, 0, -lookBack), interval.To())
+		prices, err = useCase.historicalPrices.GetHistoricalPrices(ctx, tickers, pricesInterval)
+		result.Errors = prices.Errors
+		if err != nil {
+			return result, err
+		}
+	}
+
+	values, err := ledger.Values(days, prices.Prices)
+	if err != nil {
+		return result, errors.Wrapf(err, "unable to value portfolio %s", portfolioID)
+	}
+	result.Values = values
+	result.TimeWeightedReturn = optional(analytics.TimeWeightedReturn(values))
+	result.MoneyWeightedReturn = optional(analytics.XIRR(analytics.InvestorCashFlows(values)))
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase/mocks"
+)
+
+func Test_GetPortfolioReturns(t *testing.T) {
+	anp := tickerInfoAnp.Ticker
+	store := &mocks.LedgerStore{}
+	store.On("GetLedger", mock.Anything, "isa").Return(entity.Ledger{PortfolioID: "isa", Currency: "GBP"}.Add(
+		entity.Transaction{Time: from.Add(-time.Hour), Kind: entity.Deposit, Amount: 100},
+		entity.Transaction{Time: from.Add(-time.Hour), Kind: entity.Buy, Ticker: &anp, Quantity: 100, Amount: 100},
+		entity.Transaction{Time: from.AddDate(0, 0, 1), Kind: entity.Deposit, Amount: 50},
+	), nil)
+	// Prices in pence, from 1 January 2018, a Monday.
+	priceProvider := &mocks.HistoricalPricesProvider{}
+	priceProvider.On("GetHistoricalPrices", mock.Anything, anp, mock.Anything).Return(dailyHistory(tickerInfoAnp, 100, 110, 121), nil)
+	interval, _ := entity.NewDateInterval(from, from.AddDate(0, 0, 2))
+	useCase := NewGetPortfolioReturnsUseCase(store, NewGetHistoricalPricesUseCase(priceProvider, 1, 0))
+
+	result, err := useCase.GetPortfolioReturns(context.Background(), "isa", interval)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "GBP", result.Currency)
+		assert.Equal(t, []entity.DailyValue{
+			{Time: from, Value: 100},
+			{Time: from.AddDate(0, 0, 1), Value: 160, Cash: 50, Flow: 50},
+			{Time: from.AddDate(0, 0, 2), Value: 171, Cash: 50},
+		}, result.Values)
+		assert.InDelta(t, 0.14, *result.TimeWeightedReturn, 1e-9, "the deposit earns nothing")
+		assert.NotNil(t, result.MoneyWeightedReturn)
+	}
+}
+
+func Test_GetPortfolioReturns_WHEN_NoTransactions_THEN_ReturnNotFound(t *testing.T) {
+	store := &mocks.LedgerStore{}
+	store.On("GetLedger", mock.Anything, "isa").Return(entity.Ledger{PortfolioID: "isa"}, nil)
+	interval, _ := entity.NewDateInterval(from, to)
+	useCase := NewGetPortfolioReturnsUseCase(store, NewGetHistoricalPricesUseCase(&mocks.HistoricalPricesProvider{}, 1, 0))
+
+	_, err := useCase.GetPortfolioReturns(context.Background(), "isa", interval)
+
+	assert.Equal(t, entity.NotFound, entity.KindOf(err))
+}
+
+func Test_RecordTransactions_WHEN_Invalid_THEN_SaveNothing(t *testing.T) {
+	anp := tickerInfoAnp.Ticker
+	store := &mocks.LedgerStore{}
+	store.On("GetLedger", mock.Anything, "isa").Return(entity.Ledger{PortfolioID: "isa"}, nil)
+	useCase := NewRecordTransactionsUseCase(store)
+
+	_, err := useCase.RecordTransactions(context.Background(), "isa", "GBP", []entity.Transaction{{Time: from, Kind: entity.Sell, Ticker: &anp, Quantity: 1, Amount: 1}})
+
+	assert.IsType(t, entity.ErrValidation{}, err)
+	store.AssertNotCalled(t, "SaveLedger", mock.Anything, mock.Anything)
+}
+
+func Test_RecordTransactions_THEN_SaveSortedLedger(t *testing.T) {
+	store := &mocks.LedgerStore{}
+	store.On("GetLedger", mock.Anything, "isa").Return(entity.Ledger{PortfolioID: "isa", Currency: "GBP"}.Add(entity.Transaction{Time: to, Kind: entity.Deposit, Amount: 2}), nil)
+	expected := entity.Ledger{PortfolioID: "isa", Currency: "GBP", Transactions: []entity.Transaction{
+		{Time: from, Kind: entity.Deposit, Amount: 1},
+		{Time: to, Kind: entity.Deposit, Amount: 2},
+	}, Version: 1}
+	store.On("SaveLedger", mock.Anything, expected).Return(nil)
+	useCase := NewRecordTransactionsUseCase(store)
+
+	result, err := useCase.RecordTransactions(context.Background(), "isa", "", []entity.Transaction{{Time: from, Kind: entity.Deposit, Amount: 1}})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, result)
+	}
+	_, err = useCase.RecordTransactions(context.Background(), "isa", "USD", nil)
+	assert.IsType(t, entity.ErrValidation{}, err, "the ledger is in GBP")
+}
+
+func Test_RecordTransactions_WHEN_ChangedMeanwhile_THEN_AddToTheNewLedger(t *testing.T) {
+	deposit := func(amount float64) entity.Transaction {
+		return entity.Transaction{Time: from, Kind: entity.Deposit, Amount: amount}
+	}
+	store := &mocks.LedgerStore{}
+	store.On("GetLedger", mock.Anything, "isa").Return(entity.Ledger{PortfolioID: "isa", Currency: "GBP"}, nil).Once()
+	store.On("SaveLedger", mock.Anything, mock.Anything).Return(entity.NewErrConflict("changed")).Once()
+	changed := entity.Ledger{PortfolioID: "isa", Currency: "GBP", Transactions: []entity.Transaction{deposit(2)}, Version: 1}
+	store.On("GetLedger", mock.Anything, "isa").Return(changed, nil).Once()
+	expected := entity.Ledger{PortfolioID: "isa", Currency: "GBP", Transactions: []entity.Transaction{deposit(2), deposit(1)}, Version: 2}
+	store.On("SaveLedger", mock.Anything, expected).Return(nil).Once()
+	useCase := NewRecordTransactionsUseCase(store)
+
+	result, err := useCase.RecordTransactions(context.Background(), "isa", "GBP", []entity.Transaction{deposit(1)})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, result)
+	}
+	store.AssertExpectations(t)
+}
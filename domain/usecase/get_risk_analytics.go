@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+
+	"org.alex859/stockprices/domain/analytics"
+	"org.alex859/stockprices/domain/entity"
+)
+
+type getRiskAnalyticsUseCase struct {
+	historicalPrices GetHistoricalPricesUseCase
+}
+
+// NewGetRiskAnalyticsUseCase creates a use case computing analytics over the prices given by historicalPrices,
+// whatever provider it uses. The benchmark, unless among the tickers, is fetched after them and its failure
+// only leaves beta out.
+func NewGetRiskAnalyticsUseCase(historicalPrices GetHistoricalPricesUseCase) *getRiskAnalyticsUseCase {
+	return &getRiskAnalyticsUseCase{historicalPrices: historicalPrices}
+}
+
+func (useCase *getRiskAnalyticsUseCase) GetRiskAnalytics(ctx context.Context, tickers []entity.Ticker, interval entity.DateInterval, options RiskOptions) (RiskAnalyticsResult, error) {
+	prices, err := useCase.historicalPrices.GetHistoricalPrices(ctx, tickers, interval)
+	result := RiskAnalyticsResult{Analytics: map[string]RiskAnalytics{}, Errors: prices.Errors}
+	if err != nil {
+		return result, err
+	}
+
+	var benchmark []analytics.Return
+	if options.Benchmark != nil {
+		history, found := prices.Prices[options.Benchmark.String()]
+		if !found {
+			benchmarkPrices, _ := useCase.historicalPrices.GetHistoricalPrices(ctx, []entity.Ticker{*options.Benchmark}, interval)
+			for key, tickerError := range benchmarkPrices.Errors {
+				result.Errors[key] = tickerError
+			}
+			history, found = benchmarkPrices.Prices[options.Benchmark.String()]
+		}
+		if found {
+			benchmark = analytics.LogReturns(analytics.DailyCloses(history))
+		}
+	}
+
+	for key, history := range prices.Prices {
+		result.Analytics[key] = riskAnalytics(history, benchmark, options.RiskFree)
+	}
+	return result, nil
+}
+
+// riskAnalytics computes the analytics of the history, beta only when there are benchmark returns.
+func riskAnalytics(history entity.PriceHistory, benchmark []analytics.Return, riskFree float64) RiskAnalytics {
+	closes := analytics.DailyCloses(history)
+	returns := analytics.LogReturns(closes)
+	result := RiskAnalytics{
+		TickerInfo:       history.TickerInfo,
+		PeriodReturn:     optional(analytics.PeriodReturn(closes)),
+		AnnualizedReturn: optional(analytics.AnnualizedReturn(closes)),
+		Volatility:       optional(analytics.Volatility(returns)),
+		MaxDrawdown:      analytics.MaxDrawdown(closes),
+		Sharpe:           optional(analytics.Sharpe(returns, riskFree)),
+		Sortino:          optional(analytics.Sortino(returns, riskFree)),
+		Returns:          returns,
+	}
+	if benchmark != nil {
+		result.Beta = optional(analytics.Beta(returns, benchmark))
+	}
+	return result
+}
+
+// optional returns a pointer to the value, nil when not ok.
+func optional(value float64, ok bool) *float64 {
+	if !ok {
+		return nil
+	}
+	return &value
+}
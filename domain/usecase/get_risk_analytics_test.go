@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase/mocks"
+)
+
+var tickerInfoFtse = entity.TickerInfo{Ticker: entity.Ticker{Symbol: "UKX", Market: "INDEXFTSE"}, Currency: "GBX"}
+
+func dailyHistory(info entity.TickerInfo, values ...float64) entity.PriceHistory {
+	history := entity.PriceHistory{TickerInfo: info}
+	for i, value := range values {
+		history.Prices = append(history.Prices, entity.PricePoint{Price: value, Time: from.AddDate(0, 0, i).Add(16 * time.Hour)})
+	}
+	return history
+}
+
+func Test_GetRiskAnalytics_WHEN_Benchmark_THEN_ComputeBeta(t *testing.T) {
+	priceProvider := &mocks.HistoricalPricesProvider{}
+	interval, _ := entity.NewDateInterval(from, to)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, tickerInfoAnp.Ticker, interval).Return(dailyHistory(tickerInfoAnp, 100, 110, 99, 120), nil)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, tickerInfoFtse.Ticker, interval).Return(dailyHistory(tickerInfoFtse, 100, 105, 100, 110), nil)
+	useCase := NewGetRiskAnalyticsUseCase(NewGetHistoricalPricesUseCase(priceProvider, 1, 0))
+
+	result, err := useCase.GetRiskAnalytics(context.Background(), []entity.Ticker{tickerInfoAnp.Ticker}, interval, RiskOptions{Benchmark: &tickerInfoFtse.Ticker})
+
+	if assert.NoError(t, err) {
+		assert.Len(t, result.Analytics, 1, "the benchmark is not analysed")
+		analytics := result.Analytics["LON:ANP"]
+		assert.Equal(t, tickerInfoAnp, analytics.TickerInfo)
+		assert.InDelta(t, 0.2, *analytics.PeriodReturn, 1e-9)
+		assert.InDelta(t, 0.1, analytics.MaxDrawdown.Depth, 1e-9)
+		assert.Len(t, analytics.Returns, 3)
+		assert.NotNil(t, analytics.Volatility)
+		if assert.NotNil(t, analytics.Beta) {
+			assert.True(t, *analytics.Beta > 1)
+		}
+	}
+}
+
+func Test_GetRiskAnalytics_WHEN_BenchmarkFails_THEN_LeaveBetaOut(t *testing.T) {
+	priceProvider := &mocks.HistoricalPricesProvider{}
+	interval, _ := entity.NewDateInterval(from, to)
+	var noResult entity.PriceHistory
+	priceProvider.On("GetHistoricalPrices", mock.Anything, tickerInfoAnp.Ticker, interval).Return(dailyHistory(tickerInfoAnp, 100), nil)
+	priceProvider.On("GetHistoricalPrices", mock.Anything, tickerInfoFtse.Ticker, interval).Return(noResult, entity.NewErrNothingFound(tickerInfoFtse.Ticker))
+	useCase := NewGetRiskAnalyticsUseCase(NewGetHistoricalPricesUseCase(priceProvider, 1, 0))
+
+	result, err := useCase.GetRiskAnalytics(context.Background(), []entity.Ticker{tickerInfoAnp.Ticker}, interval, RiskOptions{Benchmark: &tickerInfoFtse.Ticker})
+
+	if assert.NoError(t, err) {
+		analytics := result.Analytics["LON:ANP"]
+		assert.Nil(t, analytics.Beta)
+		assert.Nil(t, analytics.PeriodReturn, "a single price has no return")
+		assert.Equal(t, entity.NotFound, result.Errors["INDEXFTSE:UKX"].Kind)
+	}
+}
+
+func Test_GetRiskAnalytics_WHEN_NoPrices_THEN_ReturnError(t *testing.T) {
+	priceProvider := &mocks.HistoricalPricesProvider{}
+	interval, _ := entity.NewDateInterval(from, to)
+	var noResult entity.PriceHistory
+	priceProvider.On("GetHistoricalPrices", mock.Anything, tickerInfoAnp.Ticker, interval).Return(noResult, errors.New("an error occurred"))
+	useCase := NewGetRiskAnalyticsUseCase(NewGetHistoricalPricesUseCase(priceProvider, 1, 0))
+
+	result, err := useCase.GetRiskAnalytics(context.Background(), []entity.Ticker{tickerInfoAnp.Ticker}, interval, RiskOptions{})
+
+	assert.Error(t, err)
+	assert.Contains(t, result.Errors, "LON:ANP")
+}
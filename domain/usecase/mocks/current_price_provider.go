@@ -2,6 +2,7 @@
 
 package mocks
 
+import context "context"
 import entity "org.alex859/stockprices/domain/entity"
 import mock "github.com/stretchr/testify/mock"
 
@@ -10,20 +11,20 @@ type CurrentPriceProvider struct {
 	mock.Mock
 }
 
-// GetCurrentPrice provides a mock function with given fields: ticker
-func (_m *CurrentPriceProvider) GetCurrentPrice(ticker entity.Ticker) (entity.CurrentPrice, error) {
-	ret := _m.Called(ticker)
+// GetCurrentPrice provides a mock function with given fields: ctx, ticker
+func (_m *CurrentPriceProvider) GetCurrentPrice(ctx context.Context, ticker entity.Ticker) (entity.CurrentPrice, error) {
+	ret := _m.Called(ctx, ticker)
 
 	var r0 entity.CurrentPrice
-	if rf, ok := ret.Get(0).(func(entity.Ticker) entity.CurrentPrice); ok {
-		r0 = rf(ticker)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Ticker) entity.CurrentPrice); ok {
+		r0 = rf(ctx, ticker)
 	} else {
 		r0 = ret.Get(0).(entity.CurrentPrice)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(entity.Ticker) error); ok {
-		r1 = rf(ticker)
+	if rf, ok := ret.Get(1).(func(context.Context, entity.Ticker) error); ok {
+		r1 = rf(ctx, ticker)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -2,6 +2,7 @@
 
 package mocks
 
+import context "context"
 import entity "org.alex859/stockprices/domain/entity"
 import mock "github.com/stretchr/testify/mock"
 
@@ -10,20 +11,20 @@ type HistoricalPricesProvider struct {
 	mock.Mock
 }
 
-// GetHistoricalPrices provides a mock function with given fields: ticker, dateInterval
-func (_m *HistoricalPricesProvider) GetHistoricalPrices(ticker entity.Ticker, dateInterval entity.DateInterval) (entity.PriceHistory, error) {
-	ret := _m.Called(ticker, dateInterval)
+// GetHistoricalPrices provides a mock function with given fields: ctx, ticker, dateInterval
+func (_m *HistoricalPricesProvider) GetHistoricalPrices(ctx context.Context, ticker entity.Ticker, dateInterval entity.DateInterval) (entity.PriceHistory, error) {
+	ret := _m.Called(ctx, ticker, dateInterval)
 
 	var r0 entity.PriceHistory
-	if rf, ok := ret.Get(0).(func(entity.Ticker, entity.DateInterval) entity.PriceHistory); ok {
-		r0 = rf(ticker, dateInterval)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Ticker, entity.DateInterval) entity.PriceHistory); ok {
+		r0 = rf(ctx, ticker, dateInterval)
 	} else {
 		r0 = ret.Get(0).(entity.PriceHistory)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(entity.Ticker, entity.DateInterval) error); ok {
-		r1 = rf(ticker, dateInterval)
+	if rf, ok := ret.Get(1).(func(context.Context, entity.Ticker, entity.DateInterval) error); ok {
+		r1 = rf(ctx, ticker, dateInterval)
 	} else {
 		r1 = ret.Error(1)
 	}
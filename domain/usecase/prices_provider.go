@@ -1,21 +1,46 @@
 package usecase
 
 import (
+	"context"
 	"org.alex859/stockprices/domain/entity"
 )
 
 type (
 	// CurrentPriceProvider returns the current price for the given Ticker.
 	// If nothing can be found, return an ErrNothingFound error.
+	// Implementations should give up as soon as the context is done.
 	CurrentPriceProvider interface {
-		GetCurrentPrice(ticker entity.Ticker) (entity.CurrentPrice, error)
+		GetCurrentPrice(ctx context.Context, ticker entity.Ticker) (entity.CurrentPrice, error)
 	}
 
 	// HistoricalPricesProvider returns price history for a given ticker in the given date interval.
 	// Returned prices are chronologically ordered.
 	// If nothing can be found, return an ErrNothingFound error.
+	// Implementations should give up as soon as the context is done.
 	HistoricalPricesProvider interface {
-		GetHistoricalPrices(ticker entity.Ticker, dateInterval entity.DateInterval) (entity.PriceHistory, error)
+		GetHistoricalPrices(ctx context.Context, ticker entity.Ticker, dateInterval entity.DateInterval) (entity.PriceHistory, error)
+	}
+
+	// PortfolioStore keeps portfolios by ID.
+	// Implementations return an ErrNothingFound error, see entity.NewErrPortfolioNotFound, for unknown portfolios.
+	PortfolioStore interface {
+		GetPortfolio(ctx context.Context, id string) (entity.Portfolio, error)
+		SavePortfolio(ctx context.Context, portfolio entity.Portfolio) error
+	}
+
+	// LedgerStore keeps the transaction ledgers of portfolios by portfolio ID.
+	// Implementations return an empty ledger, without currency, for portfolios without transactions yet.
+	// SaveLedger only saves a ledger one Version ahead of the stored one, returning an ErrConflict error otherwise, so
+	// that changes made meanwhile are not lost.
+	LedgerStore interface {
+		GetLedger(ctx context.Context, portfolioID string) (entity.Ledger, error)
+		SaveLedger(ctx context.Context, ledger entity.Ledger) error
+	}
+
+	// PricesProvider is able to provide both current and historical prices.
+	PricesProvider interface {
+		CurrentPriceProvider
+		HistoricalPricesProvider
 	}
 )
 
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"org.alex859/stockprices/domain/entity"
+)
+
+type recordTransactionsUseCase struct {
+	store LedgerStore
+}
+
+// NewRecordTransactionsUseCase creates a use case keeping the ledgers in the given store.
+func NewRecordTransactionsUseCase(store LedgerStore) *recordTransactionsUseCase {
+	return &recordTransactionsUseCase{store: store}
+}
+
+// How many times recording transactions is tried when the ledger changes meanwhile.
+const recordAttempts = 3
+
+// RecordTransactions refuses transactions leaving the ledger invalid, e.g. selling more than held, and saves nothing then.
+// Transactions recorded meanwhile by another request are kept, the ledger being read again and the transactions added
+// to it again.
+func (useCase *recordTransactionsUseCase) RecordTransactions(ctx context.Context, portfolioID string, currency string, transactions []entity.Transaction) (entity.Ledger, error) {
+	for attempt := 1; ; attempt++ {
+		ledger, err := useCase.record(ctx, portfolioID, currency, transactions)
+		if entity.KindOf(err) != entity.Conflict || attempt == recordAttempts {
+			return ledger, err
+		}
+	}
+}
+
+func (useCase *recordTransactionsUseCase) record(ctx context.Context, portfolioID string, currency string, transactions []entity.Transaction) (entity.Ledger, error) {
+	ledger, err := useCase.store.GetLedger(ctx, portfolioID)
+	if err != nil {
+		return entity.Ledger{}, errors.Wrapf(err, "unable to load the ledger of portfolio %s", portfolioID)
+	}
+	ledger.PortfolioID = portfolioID
+	if ledger.Currency == "" {
+		ledger.Currency = currency
+	} else if currency != "" && currency != ledger.Currency {
+		return entity.Ledger{}, entity.NewErrValidation(fmt.Sprintf("The ledger of portfolio %s is in %s, not in %s", portfolioID, ledger.Currency, currency))
+	}
+
+	ledger = ledger.Add(transactions...)
+	if err = ledger.Validate(); err != nil {
+		return entity.Ledger{}, err
+	}
+	ledger.Version++
+	if err = useCase.store.SaveLedger(ctx, ledger); err != nil {
+		return entity.Ledger{}, err
+	}
+	return ledger, nil
+}
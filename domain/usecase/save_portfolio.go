@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"org.alex859/stockprices/domain/entity"
+)
+
+type savePortfolioUseCase struct {
+	store PortfolioStore
+}
+
+// NewSavePortfolioUseCase creates a use case keeping the portfolios in the given store.
+func NewSavePortfolioUseCase(store PortfolioStore) *savePortfolioUseCase {
+	return &savePortfolioUseCase{store: store}
+}
+
+// SavePortfolio refuses portfolios whose ID differs from portfolioID, an empty one being taken as portfolioID.
+func (useCase *savePortfolioUseCase) SavePortfolio(ctx context.Context, portfolioID string, portfolio entity.Portfolio) (entity.Portfolio, error) {
+	if portfolio.ID == "" {
+		portfolio.ID = portfolioID
+	} else if portfolio.ID != portfolioID {
+		return entity.Portfolio{}, entity.NewErrValidation(fmt.Sprintf("Portfolio id %s does not match %s", portfolio.ID, portfolioID))
+	}
+	if err := portfolio.Validate(); err != nil {
+		return entity.Portfolio{}, err
+	}
+	if err := useCase.store.SavePortfolio(ctx, portfolio); err != nil {
+		return entity.Portfolio{}, errors.Wrapf(err, "unable to save portfolio %s", portfolioID)
+	}
+	return portfolio, nil
+}
@@ -1,17 +1,138 @@
 package usecase
 
 import (
+	"context"
+	"org.alex859/stockprices/domain/analytics"
 	"org.alex859/stockprices/domain/entity"
 )
 
 type (
 	// GetHistoricalPricesUseCase prices history of the given stocks in the give time interval.
+	// Tickers that could not be fetched are listed in the result errors. When none could, an error is returned along with the result.
 	GetHistoricalPricesUseCase interface {
-		GetHistoricalPrices(tickers []entity.Ticker, interval entity.DateInterval) (map[string]entity.PriceHistory, error)
+		GetHistoricalPrices(ctx context.Context, tickers []entity.Ticker, interval entity.DateInterval) (HistoricalPricesResult, error)
 	}
 
 	// GetCurrentPricesUseCase prices history of the given stocks in the give time interval.
+	// Tickers that could not be fetched are listed in the result errors. When none could, an error is returned along with the result.
 	GetCurrentPricesUseCase interface {
-		GetCurrentPrices(tickers []entity.Ticker) (map[string]entity.CurrentPrice, error)
+		GetCurrentPrices(ctx context.Context, tickers []entity.Ticker) (CurrentPricesResult, error)
+	}
+
+	// GetRiskAnalyticsUseCase computes returns and risk measures of the given stocks in the given time interval.
+	// Tickers that could not be fetched are listed in the result errors. When none could, an error is returned along with the result.
+	GetRiskAnalyticsUseCase interface {
+		GetRiskAnalytics(ctx context.Context, tickers []entity.Ticker, interval entity.DateInterval, options RiskOptions) (RiskAnalyticsResult, error)
+	}
+
+	// GetCorrelationUseCase computes how the daily returns of the given stocks move together in the given time interval.
+	// Tickers that could not be fetched are listed in the result errors. When none could, an error is returned along with the result.
+	GetCorrelationUseCase interface {
+		GetCorrelation(ctx context.Context, tickers []entity.Ticker, interval entity.DateInterval) (CorrelationResult, error)
+	}
+
+	// ValuePortfolioUseCase values the holdings of a stored portfolio at their current prices.
+	// Tickers that could not be priced are listed in the result errors and left out of the totals.
+	// When none could, an error is returned along with the result.
+	ValuePortfolioUseCase interface {
+		ValuePortfolio(ctx context.Context, portfolioID string) (PortfolioValuationResult, error)
+	}
+
+	// SavePortfolioUseCase creates a portfolio or replaces its holdings and cash.
+	SavePortfolioUseCase interface {
+		SavePortfolio(ctx context.Context, portfolioID string, portfolio entity.Portfolio) (entity.Portfolio, error)
+	}
+
+	// RecordTransactionsUseCase adds transactions to the ledger of a portfolio, whose currency is set by the first call.
+	RecordTransactionsUseCase interface {
+		RecordTransactions(ctx context.Context, portfolioID string, currency string, transactions []entity.Transaction) (entity.Ledger, error)
+	}
+
+	// GetPortfolioReturnsUseCase values a portfolio every weekday of the given time interval, replaying its ledger,
+	// and computes its time and money weighted returns.
+	GetPortfolioReturnsUseCase interface {
+		GetPortfolioReturns(ctx context.Context, portfolioID string, interval entity.DateInterval) (PortfolioReturnsResult, error)
+	}
+
+	// TickerError tells why prices for a ticker could not be fetched.
+	TickerError struct {
+		Kind    entity.ErrorKind `json:"kind"`
+		Message string           `json:"message"`
+	}
+
+	// HistoricalPricesResult holds the price histories found, and the errors for the tickers without one, both by ticker.
+	HistoricalPricesResult struct {
+		Prices map[string]entity.PriceHistory `json:"prices"`
+		Errors map[string]TickerError         `json:"errors"`
+	}
+
+	// RiskOptions tells how to compute risk measures.
+	// RiskFree is the yearly risk free rate, e.g. 0.02 for 2%. Beta is computed against Benchmark, when not nil.
+	RiskOptions struct {
+		RiskFree  float64
+		Benchmark *entity.Ticker
+	}
+
+	// RiskAnalytics holds the returns and risk measures of a ticker, based on its daily closes.
+	// Measures that cannot be computed, e.g. the volatility of a ticker with a single price, are nil.
+	RiskAnalytics struct {
+		entity.TickerInfo
+		PeriodReturn     *float64           `json:"periodReturn"`
+		AnnualizedReturn *float64           `json:"annualizedReturn"`
+		Volatility       *float64           `json:"volatility"`
+		MaxDrawdown      analytics.Drawdown `json:"maxDrawdown"`
+		Sharpe           *float64           `json:"sharpe"`
+		Sortino          *float64           `json:"sortino"`
+		Beta             *float64           `json:"beta,omitempty"`
+		Returns          []analytics.Return `json:"returns"`
+	}
+
+	// RiskAnalyticsResult holds the analytics computed, and the errors for the tickers without prices, both by ticker.
+	// A benchmark without prices is listed in the errors too.
+	RiskAnalyticsResult struct {
+		Analytics map[string]RiskAnalytics `json:"analytics"`
+		Errors    map[string]TickerError   `json:"errors"`
+	}
+
+	// CorrelationResult holds the Pearson and Spearman correlations and the covariance of the daily log returns of the
+	// tickers found, in the order of Tickers, along with the errors for the tickers without prices.
+	// Returns are taken between the dates all tickers have a close on, Observations is how many there are.
+	CorrelationResult struct {
+		Tickers      []string               `json:"tickers"`
+		Observations int                    `json:"observations"`
+		Pearson      analytics.Matrix       `json:"pearson"`
+		Spearman     analytics.Matrix       `json:"spearman"`
+		Covariance   analytics.Matrix       `json:"covariance"`
+		Errors       map[string]TickerError `json:"errors"`
+	}
+
+	// PortfolioValuationResult holds the valuation of a portfolio, and the errors for the tickers without a price.
+	PortfolioValuationResult struct {
+		ID   string `json:"id"`
+		Name string `json:"name,omitempty"`
+		entity.Valuation
+		Errors map[string]TickerError `json:"errors"`
+	}
+
+	// PortfolioReturnsResult holds the daily values of a portfolio, in its ledger currency, and its returns over them.
+	// MoneyWeightedReturn is yearly, TimeWeightedReturn is over the whole interval. Returns that cannot be computed are nil.
+	PortfolioReturnsResult struct {
+		ID                  string                 `json:"id"`
+		Currency            string                 `json:"currency"`
+		Values              []entity.DailyValue    `json:"values"`
+		TimeWeightedReturn  *float64               `json:"timeWeightedReturn"`
+		MoneyWeightedReturn *float64               `json:"moneyWeightedReturn"`
+		Errors              map[string]TickerError `json:"errors"`
+	}
+
+	// CurrentPricesResult holds the current prices found, and the errors for the tickers without one, both by ticker.
+	CurrentPricesResult struct {
+		Prices map[string]entity.CurrentPrice `json:"prices"`
+		Errors map[string]TickerError         `json:"errors"`
 	}
 )
+
+// NewTickerError describes the given error for the callers of a use case.
+func NewTickerError(err error) TickerError {
+	return TickerError{Kind: entity.KindOf(err), Message: err.Error()}
+}
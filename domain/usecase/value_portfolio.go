@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+type valuePortfolioUseCase struct {
+	store         PortfolioStore
+	currentPrices GetCurrentPricesUseCase
+}
+
+// NewValuePortfolioUseCase creates a use case valuing the portfolios of the store at the prices given by currentPrices.
+func NewValuePortfolioUseCase(store PortfolioStore, currentPrices GetCurrentPricesUseCase) *valuePortfolioUseCase {
+	return &valuePortfolioUseCase{store: store, currentPrices: currentPrices}
+}
+
+func (useCase *valuePortfolioUseCase) ValuePortfolio(ctx context.Context, portfolioID string) (PortfolioValuationResult, error) {
+	result := PortfolioValuationResult{ID: portfolioID, Errors: map[string]TickerError{}}
+	portfolio, err := useCase.store.GetPortfolio(ctx, portfolioID)
+	if err != nil {
+		return result, errors.Wrapf(err, "unable to load portfolio %s", portfolioID)
+	}
+	result.Name = portfolio.Name
+
+	prices := CurrentPricesResult{Errors: map[string]TickerError{}}
+	if tickers := portfolio.Tickers(); len(tickers) > 0 {
+		prices, err = useCase.currentPrices.GetCurrentPrices(ctx, tickers)
+		result.Errors = prices.Errors
+		if err != nil {
+			return result, err
+		}
+	}
+
+	result.Valuation = portfolio.Value(prices.Prices)
+	return result, nil
+}
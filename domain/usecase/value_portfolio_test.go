@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase/mocks"
+)
+
+func Test_ValuePortfolio_WHEN_OnePriceMissing_THEN_ValueTheOthers(t *testing.T) {
+	store := &mocks.PortfolioStore{}
+	store.On("GetPortfolio", mock.Anything, "isa").Return(entity.Portfolio{
+		ID:   "isa",
+		Name: "ISA",
+		Holdings: []entity.Holding{
+			{Ticker: tickerInfoAnp.Ticker, Quantity: 100, CostBasis: 150},
+			{Ticker: tickerInfoSdry.Ticker, Quantity: 10, CostBasis: 30},
+		},
+	}, nil)
+	priceProvider := &mocks.CurrentPriceProvider{}
+	priceProvider.On("GetCurrentPrice", mock.Anything, tickerInfoAnp.Ticker).Return(entity.CurrentPrice{TickerInfo: tickerInfoAnp, Price: 200}, nil)
+	priceProvider.On("GetCurrentPrice", mock.Anything, tickerInfoSdry.Ticker).Return(entity.CurrentPrice{}, entity.NewErrNothingFound(tickerInfoSdry.Ticker))
+	useCase := NewValuePortfolioUseCase(store, NewGetCurrentPricesUseCase(priceProvider, 1, 0))
+
+	result, err := useCase.ValuePortfolio(context.Background(), "isa")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "ISA", result.Name)
+		assert.Len(t, result.Positions, 1)
+		assert.Equal(t, entity.CurrencyTotals{Positions: 200, Value: 200, CostBasis: 150, UnrealizedPnL: 50}, result.Totals["GBP"])
+		assert.Equal(t, entity.NotFound, result.Errors["LON:SDRY"].Kind)
+	}
+}
+
+func Test_ValuePortfolio_WHEN_UnknownPortfolio_THEN_ReturnNotFound(t *testing.T) {
+	store := &mocks.PortfolioStore{}
+	store.On("GetPortfolio", mock.Anything, "nope").Return(entity.Portfolio{}, entity.NewErrPortfolioNotFound("nope"))
+	useCase := NewValuePortfolioUseCase(store, NewGetCurrentPricesUseCase(&mocks.CurrentPriceProvider{}, 1, 0))
+
+	_, err := useCase.ValuePortfolio(context.Background(), "nope")
+
+	assert.Equal(t, entity.NotFound, entity.KindOf(err))
+}
+
+func Test_SavePortfolio(t *testing.T) {
+	store := &mocks.PortfolioStore{}
+	expected := entity.Portfolio{ID: "isa", Holdings: []entity.Holding{{Ticker: tickerInfoAnp.Ticker, Quantity: 100, CostBasis: 150}}}
+	store.On("SavePortfolio", mock.Anything, expected).Return(nil)
+	useCase := NewSavePortfolioUseCase(store)
+
+	result, err := useCase.SavePortfolio(context.Background(), "isa", entity.Portfolio{Holdings: expected.Holdings})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, result)
+	}
+	_, err = useCase.SavePortfolio(context.Background(), "isa", entity.Portfolio{ID: "sipp"})
+	assert.IsType(t, entity.ErrValidation{}, err)
+	_, err = useCase.SavePortfolio(context.Background(), "isa", entity.Portfolio{Holdings: []entity.Holding{{Quantity: -1}}})
+	assert.IsType(t, entity.ErrValidation{}, err)
+	store.AssertNumberOfCalls(t, "SavePortfolio", 1)
+}
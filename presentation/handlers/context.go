@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// TickerTimeout is the maximum time given to a single ticker to answer.
+	TickerTimeout = 10 * time.Second
+
+	// API Gateway gives up on the integration after 29 seconds, whatever the Lambda timeout is.
+	apiGatewayTimeout = 29 * time.Second
+
+	// Time kept to write the response before the deadline.
+	responseMargin = 500 * time.Millisecond
+)
+
+// RequestContext derives the context to serve a request with from the Lambda one.
+// Its deadline is the earliest of the Lambda and the API Gateway ones, minus the time needed to respond.
+func RequestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline := time.Now().Add(apiGatewayTimeout)
+	if lambdaDeadline, ok := ctx.Deadline(); ok && lambdaDeadline.Before(deadline) {
+		deadline = lambdaDeadline
+	}
+
+	return context.WithDeadline(ctx, deadline.Add(-responseMargin))
+}
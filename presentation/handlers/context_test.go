@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RequestContext_WHEN_LambdaDeadline_THEN_LeaveTimeToRespond(t *testing.T) {
+	lambdaDeadline := time.Now().Add(5 * time.Second)
+	lambdaCtx, cancelLambda := context.WithDeadline(context.Background(), lambdaDeadline)
+	defer cancelLambda()
+
+	ctx, cancel := RequestContext(lambdaCtx)
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if assert.True(t, ok) {
+		assert.Equal(t, lambdaDeadline.Add(-responseMargin), deadline)
+	}
+}
+
+func Test_RequestContext_WHEN_NoLambdaDeadline_THEN_UseAPIGatewayTimeout(t *testing.T) {
+	before := time.Now()
+	ctx, cancel := RequestContext(context.Background())
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if assert.True(t, ok) {
+		assert.WithinDuration(t, before.Add(apiGatewayTimeout-responseMargin), deadline, time.Second)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"org.alex859/stockprices/domain/analytics"
+	"org.alex859/stockprices/domain/usecase"
+	"org.alex859/stockprices/presentation/handlers"
+	"strconv"
+)
+
+func CorrelationHandler(lambdaCtx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ctx, cancel := handlers.RequestContext(lambdaCtx)
+	defer cancel()
+	defer handlers.LogCacheStats()
+	historicalPrices := usecase.NewGetHistoricalPricesUseCase(handlers.PricesProvider(), 5, handlers.TickerTimeout)
+	useCase := usecase.NewGetCorrelationUseCase(historicalPrices)
+
+	tickerSlice, err := handlers.Tickers(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	interval, err := handlers.Interval(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	format, err := handlers.Format(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	result, err := useCase.GetCorrelation(ctx, tickerSlice, interval)
+	if err != nil {
+		return handlers.ErrorResponse(err, result.Errors), nil
+	}
+
+	if format == handlers.CSVFormat {
+		return handlers.CSVResponse(records(result)), nil
+	}
+	return handlers.JSONResponse(result), nil
+}
+
+// records lays the matrices out one after the other, a row per ticker named after its matrix and ticker.
+// Measures that cannot be computed are left empty. Tickers that failed are not listed.
+func records(result usecase.CorrelationResult) [][]string {
+	header := append([]string{"matrix", "ticker"}, result.Tickers...)
+	records := [][]string{header}
+	matrices := []struct {
+		name   string
+		matrix analytics.Matrix
+	}{
+		{"pearson", result.Pearson},
+		{"spearman", result.Spearman},
+		{"covariance", result.Covariance},
+	}
+	for _, m := range matrices {
+		for i, row := range m.matrix {
+			record := []string{m.name, result.Tickers[i]}
+			for _, value := range row {
+				cell := ""
+				if value != nil {
+					cell = strconv.FormatFloat(*value, 'g', -1, 64)
+				}
+				record = append(record, cell)
+			}
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func main() {
+	lambda.Start(CorrelationHandler)
+}
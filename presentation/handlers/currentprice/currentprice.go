@@ -1,36 +1,31 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
-	"net/http"
-	"org.alex859/stockprices/data/googlefinance"
 	"org.alex859/stockprices/domain/usecase"
 	"org.alex859/stockprices/presentation/handlers"
 )
 
-func CurrentPricesHandler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func CurrentPricesHandler(lambdaCtx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	//configuration := ReadConfig()
-	googleFinancePriceFetcher := googlefinance.NewDefaultPricesFetcher(http.DefaultClient)
-	pricesProvider := googlefinance.NewGoogleFinancePricesProvider(googleFinancePriceFetcher, googlefinance.NewGoogleFinanceResponseConverter())
-	useCase := usecase.NewGetCurrentPricesUseCase(pricesProvider, 5)
+	ctx, cancel := handlers.RequestContext(lambdaCtx)
+	defer cancel()
+	defer handlers.LogCacheStats()
+	useCase := usecase.NewGetCurrentPricesUseCase(handlers.PricesProvider(), 5, handlers.TickerTimeout)
 
 	tickerSlice, err := handlers.Tickers(request)
 	if err != nil {
-		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 400}, nil
+		return handlers.ErrorResponse(err, nil), nil
 	}
 
-	result, err := useCase.GetCurrentPrices(tickerSlice)
+	result, err := useCase.GetCurrentPrices(ctx, tickerSlice)
 	if err != nil {
-		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
+		return handlers.ErrorResponse(err, result.Errors), nil
 	}
 
-	s, err := json.Marshal(result)
-	if err != nil {
-		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
-	}
-	return events.APIGatewayProxyResponse{Body: string(s), StatusCode: 200}, nil
+	return handlers.JSONResponse(result), nil
 }
 
 func main() {
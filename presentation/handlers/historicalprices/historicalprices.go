@@ -1,42 +1,116 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
-	"net/http"
-	"org.alex859/stockprices/data/googlefinance"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/indicator"
 	"org.alex859/stockprices/domain/usecase"
 	"org.alex859/stockprices/presentation/handlers"
 )
 
-func HistoricalPricesHandler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+// The prices of all tickers on a common time axis, along with the tickers that failed.
+type alignedResult struct {
+	entity.AlignedPrices
+	Errors map[string]usecase.TickerError `json:"errors"`
+}
+
+// A price history along with the indicators computed over it, by indicator.
+type historyWithIndicators struct {
+	entity.PriceHistory
+	Indicators map[string]indicator.Lines `json:"indicators"`
+}
+
+// The price histories with their indicators, along with the tickers that failed.
+type indicatorsResult struct {
+	Prices map[string]historyWithIndicators `json:"prices"`
+	Errors map[string]usecase.TickerError   `json:"errors"`
+}
+
+func HistoricalPricesHandler(lambdaCtx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	//configuration := ReadConfig()
-	googleFinancePriceFetcher := googlefinance.NewDefaultPricesFetcher(http.DefaultClient)
-	pricesProvider := googlefinance.NewGoogleFinancePricesProvider(googleFinancePriceFetcher, googlefinance.NewGoogleFinanceResponseConverter())
-	useCase := usecase.NewGetHistoricalPricesUseCase(pricesProvider, 5)
+	ctx, cancel := handlers.RequestContext(lambdaCtx)
+	defer cancel()
+	defer handlers.LogCacheStats()
+	useCase := usecase.NewGetHistoricalPricesUseCase(handlers.PricesProvider(), 5, handlers.TickerTimeout)
 
 	tickerSlice, err := handlers.Tickers(request)
 	if err != nil {
-		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 400}, nil
+		return handlers.ErrorResponse(err, nil), nil
 	}
 
 	interval, err := handlers.Interval(request)
 	if err != nil {
-		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 400}, nil
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	bars, err := handlers.Bars(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	resolution, resample, err := handlers.Resolution(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
 	}
 
-	result, err := useCase.GetHistoricalPrices(tickerSlice, interval)
+	location, err := handlers.TimeZone(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	alignment, align, err := handlers.Alignment(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
 
+	indicators, err := handlers.Indicators(request)
 	if err != nil {
-		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
+		return handlers.ErrorResponse(err, nil), nil
+	}
+	if align && indicators != nil {
+		return handlers.ErrorResponse(entity.NewErrValidation("Indicators are not available for aligned prices"), nil), nil
 	}
-	s, err := json.Marshal(result)
+
+	result, err := useCase.GetHistoricalPrices(ctx, tickerSlice, interval)
 	if err != nil {
-		return events.APIGatewayProxyResponse{Body: err.Error(), StatusCode: 500}, nil
+		return handlers.ErrorResponse(err, result.Errors), nil
+	}
+
+	if align {
+		// Exchanges close at different times, so prices are aligned by day unless another resolution is asked for.
+		alignment.Resolution = &entity.Resolution{Count: 1, Unit: entity.Day}
+		if resample {
+			alignment.Resolution = &resolution
+		}
+		alignment.Location = location
+		return handlers.JSONResponse(alignedResult{entity.Align(result.Prices, alignment), result.Errors}), nil
 	}
 
-	return events.APIGatewayProxyResponse{Body: string(s), StatusCode: 200}, nil
+	withIndicators := indicatorsResult{Prices: make(map[string]historyWithIndicators, len(result.Prices)), Errors: result.Errors}
+	for ticker, history := range result.Prices {
+		if resample {
+			zone := location
+			if zone == nil {
+				zone = history.Calendar().Location
+			}
+			history = history.Resample(resolution, zone, entity.LastPrice)
+		}
+		computed := indicator.ComputeAll(indicators, history)
+		if bars {
+			history = history.BarsOnly()
+		} else {
+			history = history.PricesOnly()
+		}
+		result.Prices[ticker] = history
+		withIndicators.Prices[ticker] = historyWithIndicators{history, computed}
+	}
+
+	if indicators != nil {
+		return handlers.JSONResponse(withIndicators), nil
+	}
+	return handlers.JSONResponse(result), nil
 }
 
 func main() {
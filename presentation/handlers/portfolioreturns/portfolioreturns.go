@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"org.alex859/stockprices/domain/usecase"
+	"org.alex859/stockprices/presentation/handlers"
+)
+
+func PortfolioReturnsHandler(lambdaCtx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ctx, cancel := handlers.RequestContext(lambdaCtx)
+	defer cancel()
+	defer handlers.LogCacheStats()
+	historicalPrices := usecase.NewGetHistoricalPricesUseCase(handlers.PricesProvider(), 5, handlers.TickerTimeout)
+	store, err := handlers.LedgerStore()
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+	useCase := usecase.NewGetPortfolioReturnsUseCase(store, historicalPrices)
+
+	portfolioID, err := handlers.PortfolioID(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	interval, err := handlers.Interval(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	result, err := useCase.GetPortfolioReturns(ctx, portfolioID, interval)
+	if err != nil {
+		return handlers.ErrorResponse(err, result.Errors), nil
+	}
+
+	return handlers.JSONResponse(result), nil
+}
+
+func main() {
+	lambda.Start(PortfolioReturnsHandler)
+}
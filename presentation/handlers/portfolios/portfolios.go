@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase"
+	"org.alex859/stockprices/presentation/handlers"
+)
+
+func PortfoliosHandler(lambdaCtx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ctx, cancel := handlers.RequestContext(lambdaCtx)
+	defer cancel()
+	store, err := handlers.PortfolioStore()
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+	useCase := usecase.NewSavePortfolioUseCase(store)
+
+	portfolioID, err := handlers.PortfolioID(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	var portfolio entity.Portfolio
+	if err = json.Unmarshal([]byte(request.Body), &portfolio); err != nil {
+		return handlers.ErrorResponse(entity.NewErrValidation("Invalid portfolio: "+err.Error()), nil), nil
+	}
+
+	result, err := useCase.SavePortfolio(ctx, portfolioID, portfolio)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	return handlers.JSONResponse(result), nil
+}
+
+func main() {
+	lambda.Start(PortfoliosHandler)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"org.alex859/stockprices/domain/usecase"
+	"org.alex859/stockprices/presentation/handlers"
+)
+
+func PortfolioValuationHandler(lambdaCtx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ctx, cancel := handlers.RequestContext(lambdaCtx)
+	defer cancel()
+	defer handlers.LogCacheStats()
+	currentPrices := usecase.NewGetCurrentPricesUseCase(handlers.PricesProvider(), 5, handlers.TickerTimeout)
+	store, err := handlers.PortfolioStore()
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+	useCase := usecase.NewValuePortfolioUseCase(store, currentPrices)
+
+	portfolioID, err := handlers.PortfolioID(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	result, err := useCase.ValuePortfolio(ctx, portfolioID)
+	if err != nil {
+		return handlers.ErrorResponse(err, result.Errors), nil
+	}
+
+	return handlers.JSONResponse(result), nil
+}
+
+func main() {
+	lambda.Start(PortfolioValuationHandler)
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"org.alex859/stockprices/data/cache"
+	"org.alex859/stockprices/data/coalesce"
+	"org.alex859/stockprices/data/composite"
+	"org.alex859/stockprices/data/csvfile"
+	"org.alex859/stockprices/data/googlefinance"
+	"org.alex859/stockprices/data/pandasjson"
+	"org.alex859/stockprices/data/portfoliostore"
+	"org.alex859/stockprices/data/pricestore"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase"
+)
+
+const (
+	// Directory containing end of day CSV files, see package csvfile. Leave unset to skip the CSV source.
+	csvPricesDirEnv = "CSV_PRICES_DIR"
+	// Directory containing pandas JSON exports, see package pandasjson. Leave unset to skip the JSON source.
+	pandasJSONPricesDirEnv = "PANDAS_JSON_PRICES_DIR"
+	// File where resolved Google symbols are kept across cold starts, e.g. under /tmp. Leave unset to keep them in memory only.
+	googleSymbolCacheFileEnv = "GOOGLE_SYMBOL_CACHE_FILE"
+	// Directory where daily prices from Google are stored, so that only the missing days get fetched. Leave unset to always fetch them all.
+	priceStoreDirEnv = "PRICE_STORE_DIR"
+	// How old prices can be when served in place of failing sources, as a Go duration, e.g. 24h. Set to 0 to never serve them.
+	priceMaxStalenessEnv = "PRICE_MAX_STALENESS"
+	// JSON file with exchange time zones, sessions and holidays, see entity.ReadCalendars. Markets in the file replace the
+	// built-in calendars, holidays included, which know the London and US holidays. Leave unset to use them.
+	marketCalendarsFileEnv = "MARKET_CALENDARS_FILE"
+	// Directory containing a JSON file per portfolio, and one per ledger, named after its id. Required by the portfolio
+	// handlers. It has to be writable and shared by all of their functions, e.g. an EFS mount, see serverless.yml.
+	portfoliosDirEnv = "PORTFOLIOS_DIR"
+)
+
+// Shared by all invocations of a warm Lambda, so that symbols are searched once per container at most.
+var googleSymbolCache = googlefinance.NewSymbolCache(googlefinance.DefaultDataMidTTL, googlefinance.DefaultEiTTL, os.Getenv(googleSymbolCacheFileEnv))
+
+var (
+	pricesProviderOnce sync.Once
+	pricesProvider     pricesProviderWithStats
+)
+
+// The cached price sources, along with the stats of their caches.
+type pricesProviderWithStats interface {
+	usecase.PricesProvider
+	Stats() (current cache.Stats, historical cache.Stats)
+}
+
+// PortfolioStore returns the store of portfolios shared by the handlers, failing when its directory is not configured.
+func PortfolioStore() (usecase.PortfolioStore, error) {
+	dir, err := portfoliosDir()
+	if err != nil {
+		return nil, err
+	}
+	return portfoliostore.NewFileStore(dir), nil
+}
+
+// LedgerStore returns the store of transaction ledgers shared by the handlers, kept along with the portfolios.
+func LedgerStore() (usecase.LedgerStore, error) {
+	dir, err := portfoliosDir()
+	if err != nil {
+		return nil, err
+	}
+	return portfoliostore.NewFileStore(dir), nil
+}
+
+func portfoliosDir() (string, error) {
+	dir := os.Getenv(portfoliosDirEnv)
+	if dir == "" {
+		return "", errors.Errorf("%s is not set, portfolios need a writable directory shared by all the functions", portfoliosDirEnv)
+	}
+	return dir, nil
+}
+
+// PricesProvider returns the chain of price sources shared by the handlers, behind a cache.
+// It is built once and kept by warm Lambdas, so that prices fetched by an invocation can serve the next ones.
+// Cache misses for the same ticker at the same time share a single call to the sources.
+func PricesProvider() usecase.PricesProvider {
+	pricesProviderOnce.Do(func() {
+		loadCalendars()
+		config := cache.DefaultConfig
+		config.MaxStaleness = maxStaleness(config.MaxStaleness)
+		coalesced := coalesce.NewPricesProviderCoalescer(newPricesSourcesChain(config.MaxStaleness))
+		pricesProvider = cache.NewPricesProviderCache(coalesced, config)
+	})
+	return pricesProvider
+}
+
+// LogCacheStats logs how the price caches have been used so far.
+func LogCacheStats() {
+	if pricesProvider != nil {
+		current, historical := pricesProvider.Stats()
+		log.Printf("Price cache stats. Current: %+v, historical: %+v", current, historical)
+	}
+}
+
+// loadCalendars adds the calendars in the file named by the environment to the built-in ones.
+// A missing or invalid file is only logged, the built-in calendars still work.
+func loadCalendars() {
+	fileName := os.Getenv(marketCalendarsFileEnv)
+	if fileName == "" {
+		return
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		log.Printf("Unable to open market calendars: %s. Error: %s", fileName, err)
+		return
+	}
+	defer file.Close()
+
+	calendars, err := entity.ReadCalendars(file)
+	if err != nil {
+		log.Printf("Unable to read market calendars: %s. Error: %+v", fileName, err)
+		return
+	}
+	entity.SetCalendars(calendars)
+}
+
+// maxStaleness reads how old served prices can be from the environment, falling back to the given default.
+func maxStaleness(fallback time.Duration) time.Duration {
+	value := os.Getenv(priceMaxStalenessEnv)
+	if value == "" {
+		return fallback
+	}
+	result, err := time.ParseDuration(value)
+	if err != nil || result < 0 {
+		log.Printf("Ignoring invalid %s: %q, using %s", priceMaxStalenessEnv, value, fallback)
+		return fallback
+	}
+	return result
+}
+
+// newPricesSourcesChain builds the chain of price sources.
+// Sources are tried in order for every ticker, cheap ones should come first.
+func newPricesSourcesChain(maxStaleness time.Duration) usecase.PricesProvider {
+	var sources []composite.Source
+
+	if dir := os.Getenv(csvPricesDirEnv); dir != "" {
+		sources = append(sources, composite.Source{Name: "csvfile", Historical: csvfile.NewCSVFilePricesProvider(csvfile.Config{Dir: dir})})
+	}
+
+	if dir := os.Getenv(pandasJSONPricesDirEnv); dir != "" {
+		provider := pandasjson.NewPandasJSONPricesProvider(dir, nil, pandasjson.NewDecoder(pandasjson.DefaultColumns))
+		sources = append(sources, composite.Source{Name: "pandasjson", Historical: provider})
+	}
+
+	googleFinancePriceFetcher := googlefinance.NewDefaultPricesFetcher(http.DefaultClient, googlefinance.WithSymbolCache(googleSymbolCache))
+	googleFinanceProvider := googlefinance.NewGoogleFinancePricesProvider(googleFinancePriceFetcher, googlefinance.NewGoogleFinanceResponseConverter())
+	var googleFinanceHistorical usecase.HistoricalPricesProvider = googleFinanceProvider
+	if dir := os.Getenv(priceStoreDirEnv); dir != "" {
+		googleFinanceHistorical = pricestore.NewIncrementalPricesProvider(googleFinanceProvider, pricestore.NewFileStore(dir), maxStaleness)
+	}
+	sources = append(sources, composite.Source{Name: "googlefinance", Current: googleFinanceProvider, Historical: googleFinanceHistorical})
+
+	return composite.NewCompositePricesProvider(false, sources...)
+}
@@ -4,36 +4,55 @@ import (
 	"time"
 	"github.com/aws/aws-lambda-go/events"
 	"org.alex859/stockprices/domain/entity"
-	"github.com/pkg/errors"
+	"org.alex859/stockprices/domain/indicator"
+	"fmt"
 	"strings"
+	"strconv"
+	"math"
 )
 
 var now = time.Now
 
+const (
+	// JSONFormat asks for a JSON response.
+	JSONFormat = "json"
+	// CSVFormat asks for a CSV response.
+	CSVFormat = "csv"
+)
+
 var dateLayout = "02-01-2006"
 var fromDateParam = "from"
 var toDateParam = "to"
 var tickersParam = "tickers"
+var barsParam = "bars"
+var resolutionParam = "resolution"
+var timeZoneParam = "tz"
+var alignParam = "align"
+var maxAgeParam = "maxAge"
+var axisParam = "axis"
+var indicatorsParam = "indicators"
+var benchmarkParam = "benchmark"
+var riskFreeParam = "riskFree"
+var formatParam = "format"
+var portfolioParam = "portfolio"
 
 // From date coming form the request is required. Format DD-MM-YYYY
 func FromDate(request events.APIGatewayProxyRequest) (time.Time, error) {
 	if str, ok := request.QueryStringParameters[fromDateParam]; ok {
 		result, err := time.Parse(dateLayout, str)
-		return result, errors.Wrap(err, "Invalid from date parameter")
+		return result, validationError(err, "Invalid from date parameter")
 	}
 
-	// TODO custom errors to be mapped to error codes
-	return time.Now(), errors.New("Missing from date parameter")
+	return time.Now(), entity.NewErrValidation("Missing from date parameter")
 }
 
 // To date is optional. Defaults to current time. Format DD-MM-YYYY
 func ToDate(request events.APIGatewayProxyRequest) (time.Time, error) {
 	if str, ok := request.QueryStringParameters[toDateParam]; ok {
 		result, err := time.Parse(dateLayout, str)
-		return result, errors.Wrap(err, "Invalid to date parameter")
+		return result, validationError(err, "Invalid to date parameter")
 	}
 
-	// TODO custom errors to be mapped to error codes
 	return now(), nil
 }
 
@@ -47,7 +66,7 @@ func Interval(request events.APIGatewayProxyRequest) (result entity.DateInterval
 		return
 	}
 	result, err = entity.NewDateInterval(from, to)
-	return result, errors.Wrap(err, "Invalid interval")
+	return result, validationError(err, "Invalid interval")
 }
 
 // At least one ticker has to be present in the form of "MARKET1:SYMBOL1[,MARKET2:SYMBOL2]"
@@ -63,13 +82,140 @@ func Tickers(request events.APIGatewayProxyRequest) (result []entity.Ticker, err
 		}
 
 		if len(result) == 0 {
-			err = errors.New("No valid tickers found")
+			err = entity.NewErrValidation("No valid tickers found")
 		}
 		return
 	}
 
-	err = errors.New("Missing parameter tickers")
+	err = entity.NewErrValidation("Missing parameter tickers")
 	return
 }
 
+// Bars is optional, "true" asks for OHLC bars instead of close prices. Defaults to false.
+func Bars(request events.APIGatewayProxyRequest) (bool, error) {
+	if str, ok := request.QueryStringParameters[barsParam]; ok {
+		result, err := strconv.ParseBool(str)
+		return result, validationError(err, "Invalid bars parameter")
+	}
+
+	return false, nil
+}
+
+// Resolution is optional, e.g. "1d" or "15m", see entity.ParseResolution. found is false when missing.
+func Resolution(request events.APIGatewayProxyRequest) (result entity.Resolution, found bool, err error) {
+	if str, ok := request.QueryStringParameters[resolutionParam]; ok {
+		result, err = entity.ParseResolution(str)
+		return result, true, validationError(err, "Invalid resolution parameter")
+	}
+
+	return result, false, nil
+}
+
+// Time zone is optional, an IANA name like "Europe/London" used to align resampled prices.
+// Defaults to nil, meaning the exchange time zone of every ticker.
+func TimeZone(request events.APIGatewayProxyRequest) (*time.Location, error) {
+	if str, ok := request.QueryStringParameters[timeZoneParam]; ok {
+		result, err := time.LoadLocation(str)
+		return result, validationError(err, "Invalid tz parameter")
+	}
+
+	return nil, nil
+}
+
+// Alignment is optional, align being one of "none", "ffill" and "linear", asking for the prices of all tickers on a
+// common time axis, daily unless a resolution is given.
+// maxAge bounds how old forward filled prices can be, as a Go duration like "72h", and axis is "all" (default) to have
+// the times of any ticker or "common" to have only the ones all tickers have prices at. found is false when align is missing.
+func Alignment(request events.APIGatewayProxyRequest) (result entity.AlignOptions, found bool, err error) {
+	str, found := request.QueryStringParameters[alignParam]
+	if !found {
+		return result, false, nil
+	}
+	if result.Fill.Kind, err = entity.ParseFillKind(str); err != nil {
+		return result, true, validationError(err, "Invalid align parameter")
+	}
+
+	if str, ok := request.QueryStringParameters[maxAgeParam]; ok {
+		if result.Fill.MaxAge, err = time.ParseDuration(str); err != nil || result.Fill.MaxAge < 0 {
+			return result, true, entity.NewErrValidation(fmt.Sprintf("Invalid maxAge parameter: %q", str))
+		}
+	}
+
+	switch request.QueryStringParameters[axisParam] {
+	case "", "all":
+		result.Axis = entity.UnionAxis
+	case "common":
+		result.Axis = entity.IntersectionAxis
+	default:
+		return result, true, entity.NewErrValidation(fmt.Sprintf("Invalid axis parameter: %q", request.QueryStringParameters[axisParam]))
+	}
+	return result, true, nil
+}
+
+// Indicators is optional, a comma separated list like "sma(20),rsi,macd(12,26,9)", see indicator.ParseSpecs.
+// Defaults to nil, meaning no indicators.
+func Indicators(request events.APIGatewayProxyRequest) ([]indicator.Spec, error) {
+	if str, ok := request.QueryStringParameters[indicatorsParam]; ok {
+		result, err := indicator.ParseSpecs(str)
+		return result, validationError(err, "Invalid indicators parameter")
+	}
+
+	return nil, nil
+}
+
+// Benchmark is optional, a ticker in the form of "MARKET:SYMBOL". Defaults to nil, meaning no benchmark.
+func Benchmark(request events.APIGatewayProxyRequest) (*entity.Ticker, error) {
+	if str, ok := request.QueryStringParameters[benchmarkParam]; ok {
+		components := strings.Split(strings.Replace(str, " ", "", -1), ":")
+		if len(components) != 2 || components[0] == "" || components[1] == "" {
+			return nil, entity.NewErrValidation(fmt.Sprintf("Invalid benchmark parameter: %q", str))
+		}
+		return &entity.Ticker{Market: components[0], Symbol: components[1]}, nil
+	}
+
+	return nil, nil
+}
+
+// Risk free rate is optional, a yearly rate like "0.02" for 2%. Defaults to 0.
+func RiskFreeRate(request events.APIGatewayProxyRequest) (float64, error) {
+	if str, ok := request.QueryStringParameters[riskFreeParam]; ok {
+		result, err := strconv.ParseFloat(str, 64)
+		if err == nil && (math.IsNaN(result) || math.IsInf(result, 0) || result <= -1) {
+			return 0, entity.NewErrValidation(fmt.Sprintf("Invalid riskFree parameter: %q", str))
+		}
+		return result, validationError(err, "Invalid riskFree parameter")
+	}
+
+	return 0, nil
+}
+
+// Format is optional, either "json" or "csv". Defaults to "json".
+func Format(request events.APIGatewayProxyRequest) (string, error) {
+	switch str := strings.ToLower(request.QueryStringParameters[formatParam]); str {
+	case "", JSONFormat:
+		return JSONFormat, nil
+	case CSVFormat:
+		return CSVFormat, nil
+	default:
+		return "", entity.NewErrValidation(fmt.Sprintf("Invalid format parameter: %q", str))
+	}
+}
+
+// Portfolio id is required.
+func PortfolioID(request events.APIGatewayProxyRequest) (string, error) {
+	if str, ok := request.QueryStringParameters[portfolioParam]; ok && str != "" {
+		return str, nil
+	}
+
+	return "", entity.NewErrValidation("Missing parameter portfolio")
+}
+
+// validationError turns an error caused by the request parameters into an ErrValidation, nil stays nil.
+func validationError(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return entity.NewErrValidation(fmt.Sprintf("%s: %s", message, err))
+}
+
 
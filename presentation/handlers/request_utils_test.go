@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
 )
 
 var noRequestParams = map[string]string{}
@@ -116,6 +117,116 @@ func Test_tickers(t *testing.T) {
 	}
 }
 
+func Test_bars(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]string
+		want    bool
+		wantErr bool
+	}{
+		{"No bars param defaults to prices", noRequestParams, false, false},
+		{"Bars true asks for bars", map[string]string{"bars": "true"}, true, false},
+		{"Bars false asks for prices", map[string]string{"bars": "false"}, false, false},
+		{"Invalid bars param will return error", map[string]string{"bars": "candles"}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Bars(request(tt.params))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Bars() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Bars() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resolutionAndTimeZone(t *testing.T) {
+	resolution, found, err := Resolution(request(map[string]string{"resolution": "1w"}))
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, entity.Resolution{Count: 1, Unit: entity.Week}, resolution)
+
+	_, found, err = Resolution(request(noRequestParams))
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, _, err = Resolution(request(map[string]string{"resolution": "1y"}))
+	assert.IsType(t, entity.ErrValidation{}, err)
+
+	location, err := TimeZone(request(noRequestParams))
+	assert.NoError(t, err)
+	assert.Nil(t, location)
+
+	_, err = TimeZone(request(map[string]string{"tz": "Mars/Olympus"}))
+	assert.IsType(t, entity.ErrValidation{}, err)
+}
+
+func Test_alignment(t *testing.T) {
+	options, found, err := Alignment(request(map[string]string{"align": "ffill", "maxAge": "72h", "axis": "common"}))
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, entity.AlignOptions{Fill: entity.FillPolicy{Kind: entity.ForwardFill, MaxAge: 72 * time.Hour}, Axis: entity.IntersectionAxis}, options)
+
+	_, found, err = Alignment(request(noRequestParams))
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	for _, params := range []map[string]string{{"align": "spline"}, {"align": "ffill", "maxAge": "3 days"}, {"align": "none", "axis": "some"}} {
+		_, _, err = Alignment(request(params))
+		assert.IsType(t, entity.ErrValidation{}, err, "%v", params)
+	}
+}
+
+func Test_indicators(t *testing.T) {
+	specs, err := Indicators(request(map[string]string{"indicators": "sma(20),macd"}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sma(20)", "macd(12,26,9)"}, []string{specs[0].String(), specs[1].String()})
+
+	specs, err = Indicators(request(noRequestParams))
+	assert.NoError(t, err)
+	assert.Nil(t, specs)
+
+	_, err = Indicators(request(map[string]string{"indicators": "sma(0)"}))
+	assert.IsType(t, entity.ErrValidation{}, err)
+}
+
+func Test_benchmarkAndRiskFreeRate(t *testing.T) {
+	benchmark, err := Benchmark(request(map[string]string{"benchmark": "INDEXFTSE:UKX"}))
+	assert.NoError(t, err)
+	assert.Equal(t, &entity.Ticker{Market: "INDEXFTSE", Symbol: "UKX"}, benchmark)
+	_, err = Benchmark(request(map[string]string{"benchmark": "UKX"}))
+	assert.IsType(t, entity.ErrValidation{}, err)
+
+	riskFree, err := RiskFreeRate(request(map[string]string{"riskFree": "0.02"}))
+	assert.NoError(t, err)
+	assert.Equal(t, 0.02, riskFree)
+	riskFree, err = RiskFreeRate(request(noRequestParams))
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, riskFree)
+	_, err = RiskFreeRate(request(map[string]string{"riskFree": "2%"}))
+	assert.IsType(t, entity.ErrValidation{}, err)
+	for _, str := range []string{"NaN", "Inf", "+Inf", "-1"} {
+		_, err = RiskFreeRate(request(map[string]string{"riskFree": str}))
+		assert.IsType(t, entity.ErrValidation{}, err, str)
+	}
+}
+
+func Test_format(t *testing.T) {
+	format, err := Format(request(noRequestParams))
+	assert.NoError(t, err)
+	assert.Equal(t, JSONFormat, format)
+
+	format, err = Format(request(map[string]string{"format": "CSV"}))
+	assert.NoError(t, err)
+	assert.Equal(t, CSVFormat, format)
+
+	_, err = Format(request(map[string]string{"format": "xml"}))
+	assert.IsType(t, entity.ErrValidation{}, err)
+}
+
 func request(params map[string]string) events.APIGatewayProxyRequest {
 	return events.APIGatewayProxyRequest{QueryStringParameters:params}
 }
\ No newline at end of file
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase"
+)
+
+const (
+	jsonContentType    = "application/json"
+	csvContentType     = "text/csv"
+	problemContentType = "application/problem+json"
+)
+
+// internalErrorDetail stands for the detail of unexpected errors, which are logged rather than shown to clients.
+const internalErrorDetail = "The request could not be served because of an unexpected error"
+
+// Problem is the body of error responses, as described by RFC 7807.
+// Errors lists the per-ticker failures, when the request got as far as querying prices.
+type Problem struct {
+	Type   string                         `json:"type"`
+	Title  string                         `json:"title"`
+	Status int                            `json:"status"`
+	Detail string                         `json:"detail,omitempty"`
+	Errors map[string]usecase.TickerError `json:"errors,omitempty"`
+}
+
+// JSONResponse writes the given value as a 200 JSON response.
+func JSONResponse(value interface{}) events.APIGatewayProxyResponse {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return ErrorResponse(errors.Wrap(err, "unable to write response"), nil)
+	}
+
+	return events.APIGatewayProxyResponse{
+		Body:       string(body),
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": jsonContentType},
+	}
+}
+
+// CSVResponse writes the given records as a 200 CSV response.
+func CSVResponse(records [][]string) events.APIGatewayProxyResponse {
+	var body bytes.Buffer
+	if err := csv.NewWriter(&body).WriteAll(records); err != nil {
+		return ErrorResponse(errors.Wrap(err, "unable to write response"), nil)
+	}
+
+	return events.APIGatewayProxyResponse{
+		Body:       body.String(),
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": csvContentType},
+	}
+}
+
+// ErrorResponse writes the given error as a problem details response, its status depending on the cause of the error.
+// Unexpected errors are logged and only reported with a generic detail.
+// A throttled upstream also tells clients when to come back through a Retry-After header, when known.
+func ErrorResponse(err error, tickerErrors map[string]usecase.TickerError) events.APIGatewayProxyResponse {
+	status := StatusOf(err)
+	detail := err.Error()
+	if status == http.StatusInternalServerError {
+		log.Printf("Unexpected error serving request. Error: %+v", err)
+		detail = internalErrorDetail
+	}
+
+	problem := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Errors: tickerErrors,
+	}
+	body, marshalErr := json.Marshal(problem)
+	if marshalErr != nil {
+		body = []byte(`{"type":"about:blank","title":"Internal Server Error","status":500}`)
+		status = http.StatusInternalServerError
+	}
+
+	headers := map[string]string{"Content-Type": problemContentType}
+	if throttled, ok := errors.Cause(err).(entity.ErrUpstreamThrottled); ok && throttled.RetryAfter > 0 {
+		headers["Retry-After"] = strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds())))
+	}
+
+	return events.APIGatewayProxyResponse{Body: string(body), StatusCode: status, Headers: headers}
+}
+
+// StatusOf maps an error to the HTTP status reporting it.
+func StatusOf(err error) int {
+	switch cause := errors.Cause(err); cause.(type) {
+	case entity.ErrValidation:
+		return http.StatusBadRequest
+	case entity.ErrNothingFound:
+		return http.StatusNotFound
+	case entity.ErrConflict:
+		return http.StatusConflict
+	case entity.ErrUpstreamFailure, entity.ErrMalformedData:
+		return http.StatusBadGateway
+	case entity.ErrUpstreamThrottled:
+		return http.StatusServiceUnavailable
+	default:
+		if cause == context.DeadlineExceeded || cause == context.Canceled {
+			return http.StatusGatewayTimeout
+		}
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase"
+)
+
+func Test_StatusOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"validation", entity.NewErrValidation("Missing parameter tickers"), 400},
+		{"nothing found", errors.Wrap(entity.NewErrNothingFound(entity.Ticker{Market: "LON", Symbol: "ANP"}), "no source"), 404},
+		{"conflict", entity.NewErrConflict("The ledger of portfolio isa changed meanwhile"), 409},
+		{"upstream failure", entity.NewErrUpstreamFailure("google finance", "503"), 502},
+		{"malformed data", entity.NewErrMalformedData("google finance", "no rows"), 502},
+		{"throttled", entity.NewErrUpstreamThrottled("google finance", "429", 0), 503},
+		{"deadline", context.DeadlineExceeded, 504},
+		{"anything else", errors.New("unable to fetch stock prices"), 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, StatusOf(tt.err))
+		})
+	}
+}
+
+func Test_ErrorResponse_WHEN_Throttled_THEN_ProblemWithRetryAfter(t *testing.T) {
+	err := errors.Wrap(entity.NewErrUpstreamThrottled("google finance", "429", 1500*time.Millisecond), "unable to get prices")
+	tickerErrors := map[string]usecase.TickerError{"LON:ANP": {Kind: entity.Throttled, Message: "429"}}
+
+	response := ErrorResponse(err, tickerErrors)
+
+	assert.Equal(t, 503, response.StatusCode)
+	assert.Equal(t, "application/problem+json", response.Headers["Content-Type"])
+	assert.Equal(t, "2", response.Headers["Retry-After"])
+	var problem Problem
+	if assert.NoError(t, json.Unmarshal([]byte(response.Body), &problem)) {
+		assert.Equal(t, Problem{
+			Type:   "about:blank",
+			Title:  "Service Unavailable",
+			Status: 503,
+			Detail: err.Error(),
+			Errors: tickerErrors,
+		}, problem)
+	}
+}
+
+func Test_ErrorResponse_WHEN_ValidationError_THEN_BadRequestWithoutRetryAfter(t *testing.T) {
+	response := ErrorResponse(entity.NewErrValidation("Missing parameter tickers"), nil)
+
+	assert.Equal(t, 400, response.StatusCode)
+	assert.NotContains(t, response.Headers, "Retry-After")
+	assert.JSONEq(t, `{"type":"about:blank","title":"Bad Request","status":400,"detail":"Missing parameter tickers"}`, response.Body)
+}
+
+func Test_ErrorResponse_WHEN_UnexpectedError_THEN_HideIt(t *testing.T) {
+	response := ErrorResponse(errors.New("open /mnt/portfolios/isa.json: permission denied"), nil)
+
+	assert.Equal(t, 500, response.StatusCode)
+	assert.NotContains(t, response.Body, "/mnt/portfolios")
+	assert.Contains(t, response.Body, internalErrorDetail)
+}
+
+func Test_JSONResponse_WHEN_AllGood_THEN_OK(t *testing.T) {
+	response := JSONResponse(map[string]int{"a": 1})
+
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "application/json", response.Headers["Content-Type"])
+	assert.Equal(t, `{"a":1}`, response.Body)
+}
+
+func Test_CSVResponse(t *testing.T) {
+	response := CSVResponse([][]string{{"matrix", "ticker", "LON:ANP"}, {"pearson", "LON:ANP", "1"}})
+
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "text/csv", response.Headers["Content-Type"])
+	assert.Equal(t, "matrix,ticker,LON:ANP\npearson,LON:ANP,1\n", response.Body)
+}
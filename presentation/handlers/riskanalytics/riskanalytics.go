@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"org.alex859/stockprices/domain/usecase"
+	"org.alex859/stockprices/presentation/handlers"
+)
+
+func RiskAnalyticsHandler(lambdaCtx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ctx, cancel := handlers.RequestContext(lambdaCtx)
+	defer cancel()
+	defer handlers.LogCacheStats()
+	historicalPrices := usecase.NewGetHistoricalPricesUseCase(handlers.PricesProvider(), 5, handlers.TickerTimeout)
+	useCase := usecase.NewGetRiskAnalyticsUseCase(historicalPrices)
+
+	tickerSlice, err := handlers.Tickers(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	interval, err := handlers.Interval(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	benchmark, err := handlers.Benchmark(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	riskFree, err := handlers.RiskFreeRate(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	result, err := useCase.GetRiskAnalytics(ctx, tickerSlice, interval, usecase.RiskOptions{RiskFree: riskFree, Benchmark: benchmark})
+	if err != nil {
+		return handlers.ErrorResponse(err, result.Errors), nil
+	}
+
+	return handlers.JSONResponse(result), nil
+}
+
+func main() {
+	lambda.Start(RiskAnalyticsHandler)
+}
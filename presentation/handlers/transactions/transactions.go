@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"org.alex859/stockprices/domain/entity"
+	"org.alex859/stockprices/domain/usecase"
+	"org.alex859/stockprices/presentation/handlers"
+)
+
+// The body of the request: the transactions to record and the currency of their amounts.
+type transactionsRequest struct {
+	Currency     string               `json:"currency"`
+	Transactions []entity.Transaction `json:"transactions"`
+}
+
+func TransactionsHandler(lambdaCtx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ctx, cancel := handlers.RequestContext(lambdaCtx)
+	defer cancel()
+	store, err := handlers.LedgerStore()
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+	useCase := usecase.NewRecordTransactionsUseCase(store)
+
+	portfolioID, err := handlers.PortfolioID(request)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	var body transactionsRequest
+	if err = json.Unmarshal([]byte(request.Body), &body); err != nil {
+		return handlers.ErrorResponse(entity.NewErrValidation("Invalid transactions: "+err.Error()), nil), nil
+	}
+
+	ledger, err := useCase.RecordTransactions(ctx, portfolioID, body.Currency, body.Transactions)
+	if err != nil {
+		return handlers.ErrorResponse(err, nil), nil
+	}
+
+	return handlers.JSONResponse(ledger), nil
+}
+
+func main() {
+	lambda.Start(TransactionsHandler)
+}